@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+
+	kit "github.com/rarimo/zkverifier-kit"
+	"github.com/rarimo/zkverifier-kit/logging"
+	"github.com/rarimo/zkverifier-kit/metrics"
+	"gitlab.com/distributed_lab/kit/comfig"
+	"gitlab.com/distributed_lab/kit/kv"
+)
+
+// policiesKey is the config map with verifier policies by name, see
+// kit.NewVerifierFromConfig for the policy format
+const policiesKey = "verifiers"
+
+type config struct {
+	comfig.Logger
+	comfig.Listenerer
+	getter  kv.Getter
+	metrics *metrics.Metrics
+}
+
+func newConfig(getter kv.Getter) *config {
+	return &config{
+		Logger:     comfig.NewLogger(getter, comfig.LoggerOpts{}),
+		Listenerer: comfig.NewListenerer(getter),
+		getter:     getter,
+		metrics:    metrics.New(""),
+	}
+}
+
+// Verifiers creates a verifier for each policy in the config map
+func (c *config) Verifiers() map[string]*kit.Verifier {
+	policies := kv.MustGetStringMap(c.getter, policiesKey)
+	verifiers := make(map[string]*kit.Verifier, len(policies))
+
+	for name := range policies {
+		v, err := kit.NewVerifierFromConfig(c.getter, policiesKey+"."+name,
+			kit.WithObserver(c.metrics),
+			kit.WithLogger(logging.FromLogan(c.Log().WithField("policy", name))),
+		)
+		if err != nil {
+			panic(fmt.Errorf("failed to create verifier for policy %s: %w", name, err))
+		}
+
+		verifiers[name] = v
+	}
+
+	return verifiers
+}
@@ -0,0 +1,30 @@
+// Command zkverifier serves zkverifier-kit over REST API, so the services in
+// other languages could verify the proofs. Verifier policies are loaded from
+// the config file in KV_VIPER_FILE, see config.example.yaml.
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"gitlab.com/distributed_lab/kit/kv"
+)
+
+func main() {
+	cfg := newConfig(kv.MustFromEnv())
+	log := cfg.Log()
+
+	verifiers := cfg.Verifiers()
+	log.WithField("policies", len(verifiers)).Info("verifier policies loaded")
+
+	srv := &http.Server{
+		Handler:           newRouter(verifiers, cfg.metrics),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	listener := cfg.Listener()
+	log.WithField("addr", listener.Addr().String()).Info("starting zkverifier service")
+	if err := srv.Serve(listener); err != nil {
+		log.WithError(err).Fatal("zkverifier service stopped")
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	val "github.com/go-ozzo/ozzo-validation/v4"
+	zkptypes "github.com/iden3/go-rapidsnark/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	kit "github.com/rarimo/zkverifier-kit"
+	"github.com/rarimo/zkverifier-kit/internal/signals"
+	"github.com/rarimo/zkverifier-kit/middleware"
+)
+
+// verifyRequest is the body of verify and decode endpoints
+type verifyRequest struct {
+	Proof zkptypes.ZKProof `json:"proof"`
+	// EventData is a hex-encoded event data expected in the proof, optional
+	EventData string `json:"event_data,omitempty"`
+	// RarimoAddress is a bech32 address expected in the event data, optional
+	RarimoAddress string `json:"rarimo_address,omitempty"`
+}
+
+// errInvalidRequest marks the errors of request options, which are rendered
+// with 400 status
+var errInvalidRequest = errors.New("invalid request")
+
+type service struct {
+	verifiers map[string]*kit.Verifier
+}
+
+func newRouter(verifiers map[string]*kit.Verifier, metrics prometheus.Collector) http.Handler {
+	s := &service{verifiers: verifiers}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(metrics)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", s.health)
+	mux.Handle("GET /metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	mux.HandleFunc("POST /v1/verify/{policy}", s.verify)
+	mux.HandleFunc("POST /v1/decode/{policy}", s.decode)
+
+	return mux
+}
+
+func (s *service) health(w http.ResponseWriter, _ *http.Request) {
+	policies := make([]string, 0, len(s.verifiers))
+	for name := range s.verifiers {
+		policies = append(policies, name)
+	}
+	sort.Strings(policies)
+
+	writeJSON(w, http.StatusOK, map[string]any{"status": "ok", "policies": policies})
+}
+
+// verify checks the proof against the policy and responds with its public
+// signals on success
+func (s *service) verify(w http.ResponseWriter, r *http.Request) {
+	v, ok := s.policy(w, r)
+	if !ok {
+		return
+	}
+
+	handler := middleware.New(v,
+		middleware.WithExtractor(middleware.FromBody("proof")),
+		middleware.WithOptions(requestOptions),
+		middleware.WithErrorWriter(writeError),
+	)
+
+	handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pub, _ := middleware.Signals(r.Context())
+		writeJSON(w, http.StatusOK, map[string]any{
+			"valid":   true,
+			"signals": signals.Map(pub),
+		})
+	})).ServeHTTP(w, r)
+}
+
+// decode responds with public signals of the proof by the policy proof type
+// without verification
+func (s *service) decode(w http.ResponseWriter, r *http.Request) {
+	v, ok := s.policy(w, r)
+	if !ok {
+		return
+	}
+
+	var req verifyRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		middleware.WriteJSONAPIError(w, r, val.Errors{
+			"proof": &kit.Error{Code: kit.CodeProofInvalid, Err: fmt.Errorf("decode request: %w", err)},
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"signals": signals.Map(v.Signals(req.Proof))})
+}
+
+func (s *service) policy(w http.ResponseWriter, r *http.Request) (*kit.Verifier, bool) {
+	name := r.PathValue("policy")
+	v, ok := s.verifiers[name]
+	if !ok {
+		writeStatus(w, http.StatusNotFound, fmt.Sprintf("unknown policy %q", name))
+	}
+	return v, ok
+}
+
+// requestOptions reads optional event data from the request body, which was
+// restored by middleware.FromBody
+func requestOptions(r *http.Request) ([]kit.VerifyOption, error) {
+	var req verifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("%w: decode request: %w", errInvalidRequest, err)
+	}
+
+	var opts []kit.VerifyOption
+	if req.EventData != "" {
+		raw, err := hexutil.Decode(req.EventData)
+		if err != nil {
+			return nil, fmt.Errorf("%w: decode event data: %w", errInvalidRequest, err)
+		}
+		opts = append(opts, kit.WithEventData(raw))
+	}
+	if req.RarimoAddress != "" {
+		opts = append(opts, kit.WithRarimoAddress(req.RarimoAddress))
+	}
+
+	return opts, nil
+}
+
+// writeError renders malformed request body with 400 status, and the others
+// as the middleware does
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, errInvalidRequest) {
+		writeStatus(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	middleware.WriteJSONAPIError(w, r, err)
+}
+
+func writeStatus(w http.ResponseWriter, status int, detail string) {
+	writeJSON(w, status, map[string]any{"errors": []kit.JSONAPIError{{
+		Title:  http.StatusText(status),
+		Detail: detail,
+		Status: strconv.Itoa(status),
+	}}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	zkptypes "github.com/iden3/go-rapidsnark/types"
+	kit "github.com/rarimo/zkverifier-kit"
+	"github.com/rarimo/zkverifier-kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testEventID = "304358862882731539112827930982999386691702727710421481944329166126417129570"
+
+func newTestRouter(t *testing.T) http.Handler {
+	m := metrics.New("")
+	v, err := kit.NewVerifier(nil,
+		kit.WithVerificationKeyFile("../../example_verification_key.json"),
+		kit.WithEventID(testEventID),
+		kit.WithObserver(m),
+	)
+	require.NoError(t, err)
+
+	return newRouter(map[string]*kit.Verifier{"adult": v}, m)
+}
+
+// testProof is a GlobalPassport proof with the event ID and zero signals
+func testProof(eventID string) zkptypes.ZKProof {
+	signals := make([]string, 23)
+	for i := range signals {
+		signals[i] = "0"
+	}
+	signals[0] = "12345"
+	signals[9] = eventID
+
+	return zkptypes.ZKProof{
+		Proof: &zkptypes.ProofData{
+			A:        []string{"1", "2", "1"},
+			B:        [][]string{{"1", "2"}, {"3", "4"}, {"1", "0"}},
+			C:        []string{"1", "2", "1"},
+			Protocol: "groth16",
+		},
+		PubSignals: signals,
+	}
+}
+
+func do(t *testing.T, h http.Handler, method, path string, body any) (int, map[string]any) {
+	var raw []byte
+	switch b := body.(type) {
+	case nil:
+	case string:
+		raw = []byte(b)
+	default:
+		var err error
+		raw, err = json.Marshal(b)
+		require.NoError(t, err)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, path, bytes.NewReader(raw)))
+
+	var resp map[string]any
+	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+	return rec.Code, resp
+}
+
+func errorCodes(resp map[string]any) []any {
+	var codes []any
+	errs, _ := resp["errors"].([]any)
+	for _, e := range errs {
+		codes = append(codes, e.(map[string]any)["code"])
+	}
+	return codes
+}
+
+func TestService(t *testing.T) {
+	h := newTestRouter(t)
+
+	status, resp := do(t, h, http.MethodGet, "/health", nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []any{"adult"}, resp["policies"])
+
+	status, _ = do(t, h, http.MethodPost, "/v1/verify/unknown", verifyRequest{Proof: testProof(testEventID)})
+	assert.Equal(t, http.StatusNotFound, status)
+
+	// groth16 fails on the fake proof, when all the signals are valid
+	status, resp = do(t, h, http.MethodPost, "/v1/verify/adult", verifyRequest{Proof: testProof(testEventID)})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, []any{string(kit.CodeGroth16Failed)}, errorCodes(resp))
+
+	status, resp = do(t, h, http.MethodPost, "/v1/verify/adult", verifyRequest{Proof: testProof("1")})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, []any{string(kit.CodeEventIDMismatch)}, errorCodes(resp))
+
+	status, resp = do(t, h, http.MethodPost, "/v1/verify/adult", verifyRequest{
+		Proof:     testProof(testEventID),
+		EventData: "0x01",
+	})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, []any{string(kit.CodeEventDataMismatch)}, errorCodes(resp))
+
+	status, _ = do(t, h, http.MethodPost, "/v1/decode/adult", verifyRequest{Proof: testProof("1")})
+	assert.Equal(t, http.StatusOK, status)
+
+	status, resp = do(t, h, http.MethodPost, "/v1/decode/adult", "{")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, []any{string(kit.CodeProofInvalid)}, errorCodes(resp))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `zkverifier_verifications_total{outcome="event_id_mismatch",proof_type="global_passport"} 1`)
+}
+
+func TestServiceBadRequest(t *testing.T) {
+	h := newTestRouter(t)
+
+	for name, tc := range map[string]struct {
+		req  verifyRequest
+		code any
+	}{
+		"invalid event data hex": {req: verifyRequest{Proof: testProof(testEventID), EventData: "0xzz"}},
+		"event data with address": {
+			req: verifyRequest{
+				Proof:         testProof(testEventID),
+				EventData:     "0x01",
+				RarimoAddress: "rarimo1h2077nvtrkmr2gqmy6q3sgvnmhdvjs2qsmnakg",
+			},
+			code: string(kit.CodeOptionsInvalid),
+		},
+		"invalid address": {
+			req:  verifyRequest{Proof: testProof(testEventID), RarimoAddress: "cosmos1abc"},
+			code: string(kit.CodeOptionsInvalid),
+		},
+	} {
+		status, resp := do(t, h, http.MethodPost, "/v1/verify/adult", tc.req)
+		assert.Equal(t, http.StatusBadRequest, status, name)
+		if assert.NotEmpty(t, resp["errors"], name) {
+			assert.Equal(t, tc.code, errorCodes(resp)[0], name)
+		}
+	}
+}
+
+func TestRequestOptions(t *testing.T) {
+	for name, tc := range map[string]struct {
+		body string
+		opts int
+		err  bool
+	}{
+		"empty":      {body: `{"proof":{}}`},
+		"event data": {body: `{"event_data":"0x0102"}`, opts: 1},
+		"address":    {body: `{"rarimo_address":"rarimo1abc"}`, opts: 1},
+		"both":       {body: `{"event_data":"0x01","rarimo_address":"rarimo1abc"}`, opts: 2},
+		"bad hex":    {body: `{"event_data":"0102"}`, err: true},
+		"bad json":   {body: `{`, err: true},
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(tc.body))
+		opts, err := requestOptions(r)
+		if tc.err {
+			assert.ErrorIs(t, err, errInvalidRequest, name)
+			continue
+		}
+		assert.NoError(t, err, name)
+		assert.Len(t, opts, tc.opts, name)
+	}
+}
@@ -0,0 +1,200 @@
+// Command zkverify verifies the proof offline and prints its decoded public
+// signals with the outcome of each rule. It is intended for debugging:
+//
+//	zkverify -proof proof.json -key key.json -age-above 18 -citizenship UKR -no-root
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	val "github.com/go-ozzo/ozzo-validation/v4"
+	zkptypes "github.com/iden3/go-rapidsnark/types"
+	kit "github.com/rarimo/zkverifier-kit"
+	"github.com/rarimo/zkverifier-kit/internal/signals"
+	"github.com/rarimo/zkverifier-kit/root"
+)
+
+// Exit codes: proof is invalid by some rule or the verification failed
+// internally, e.g. on bad arguments or RPC failure
+const (
+	exitInvalid  = 1
+	exitInternal = 2
+)
+
+type stringsFlag []string
+
+func (s *stringsFlag) String() string { return strings.Join(*s, ",") }
+
+func (s *stringsFlag) Set(value string) error {
+	*s = append(*s, strings.Split(value, ",")...)
+	return nil
+}
+
+type flags struct {
+	proof, key, proofType string
+	ageAbove              int
+	citizenships          stringsFlag
+	eventID, selector     string
+	noRoot                bool
+	rpc, contract         string
+	timeout               time.Duration
+}
+
+func main() {
+	var f flags
+	flag.StringVar(&f.proof, "proof", "", "path to proof JSON file, required")
+	flag.StringVar(&f.key, "key", "", "path to verification key file, required")
+	flag.StringVar(&f.proofType, "type", "global_passport", "proof type: global_passport, georgian_passport or poll_participation")
+	flag.IntVar(&f.ageAbove, "age-above", -1, "minimal age of the passport owner")
+	flag.Var(&f.citizenships, "citizenship", "allowed citizenship or country group, repeatable or comma-separated")
+	flag.StringVar(&f.eventID, "event-id", "", "expected event ID")
+	flag.StringVar(&f.selector, "selector", "", "expected proof selector")
+	flag.BoolVar(&f.noRoot, "no-root", false, "skip root verification, RPC is not used")
+	flag.StringVar(&f.rpc, "rpc", "", "RPC URL for root verification")
+	flag.StringVar(&f.contract, "contract", "", "PoseidonSMT or ProposalSMT contract address for root verification")
+	flag.DurationVar(&f.timeout, "timeout", 5*time.Second, "RPC request timeout")
+	flag.Parse()
+
+	os.Exit(run(f, os.Stdout))
+}
+
+func run(f flags, out io.Writer) int {
+	proof, v, err := setup(f)
+	if err != nil {
+		fmt.Fprintln(out, "error:", err)
+		return exitInternal
+	}
+
+	err = v.VerifyProof(proof)
+
+	var errs val.Errors
+	if err != nil && !errors.As(err, &errs) {
+		fmt.Fprintln(out, "error:", err)
+		return exitInternal
+	}
+
+	printReport(out, v.Signals(proof), errs)
+	if len(errs) != 0 {
+		return exitInvalid
+	}
+	return 0
+}
+
+func setup(f flags) (proof zkptypes.ZKProof, v *kit.Verifier, err error) {
+	if f.proof == "" || f.key == "" {
+		return proof, nil, errors.New("both -proof and -key are required")
+	}
+
+	raw, err := os.ReadFile(f.proof)
+	if err != nil {
+		return proof, nil, fmt.Errorf("read proof: %w", err)
+	}
+	if err = json.Unmarshal(raw, &proof); err != nil {
+		return proof, nil, fmt.Errorf("decode proof: %w", err)
+	}
+
+	typ, err := kit.ParseProofType(f.proofType)
+	if err != nil {
+		return proof, nil, err
+	}
+
+	opts := []kit.VerifyOption{
+		kit.WithProofType(typ),
+		kit.WithVerificationKeyFile(f.key),
+		kit.WithEventID(f.eventID),
+		kit.WithProofSelectorValue(f.selector),
+	}
+	if f.ageAbove != -1 {
+		opts = append(opts, kit.WithAgeAbove(f.ageAbove))
+	}
+	if len(f.citizenships) != 0 {
+		opts = append(opts, kit.WithCitizenships(f.citizenships...))
+	}
+
+	if !f.noRoot {
+		rootOpt, err := rootVerifier(f)
+		if err != nil {
+			return proof, nil, err
+		}
+		opts = append(opts, rootOpt)
+	}
+
+	v, err = kit.NewVerifier(nil, opts...)
+	return proof, v, err
+}
+
+func rootVerifier(f flags) (kit.VerifyOption, error) {
+	if f.rpc == "" {
+		return nil, errors.New("-rpc is required for root verification, use -no-root to skip it")
+	}
+
+	if f.proofType == kit.PollParticipation.String() {
+		rv := root.NewProposalSMTVerifier(f.rpc, f.timeout).WithContract(f.contract)
+		return kit.WithPollRootVerifier(rv), nil
+	}
+
+	rv, err := root.NewPoseidonSMTVerifier(f.rpc, f.contract, f.timeout)
+	if err != nil {
+		return nil, fmt.Errorf("create root verifier: %w", err)
+	}
+	return kit.WithPassportRootVerifier(rv), nil
+}
+
+// printReport prints a table of public signals with rule outcomes, followed by
+// the failures not bound to a single signal, e.g. Groth16 verification
+func printReport(out io.Writer, pub kit.PubSignalGetter, errs val.Errors) {
+	reported := make(map[string]bool)
+
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "INDEX\tSIGNAL\tVALUE\tDECODED\tRESULT")
+	for _, s := range signals.Decode(pub) {
+		key := "pub_signals/" + s.Name
+		reported[key] = true
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", s.Index, s.Name, s.Value, s.Decoded, outcome(errs[key]))
+	}
+	_ = tw.Flush()
+
+	var other []string
+	for key := range errs {
+		if !reported[key] {
+			other = append(other, key)
+		}
+	}
+	sort.Strings(other)
+
+	if len(other) != 0 {
+		fmt.Fprintln(out)
+		tw = tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "CHECK\tRESULT")
+		for _, key := range other {
+			fmt.Fprintf(tw, "%s\t%s\n", key, outcome(errs[key]))
+		}
+		_ = tw.Flush()
+	}
+
+	fmt.Fprintln(out)
+	if len(errs) != 0 {
+		fmt.Fprintln(out, "proof is INVALID")
+		return
+	}
+	fmt.Fprintln(out, "proof is valid")
+}
+
+func outcome(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	if code := kit.ErrorCodeOf(err); code != "" {
+		return fmt.Sprintf("FAIL [%s] %s", code, err)
+	}
+	return "FAIL " + err.Error()
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testProofFile = "testdata/proof.json"
+	testKeyFile   = "../../example_verification_key.json"
+	testEventID   = "304358862882731539112827930982999386691702727710421481944329166126417129570"
+)
+
+// row finds the report row of the signal and returns its columns
+func row(t *testing.T, report, signal string) []string {
+	for _, line := range strings.Split(report, "\n") {
+		cols := regexp.MustCompile(`\s{2,}`).Split(strings.TrimSpace(line), -1)
+		if len(cols) > 1 && cols[1] == signal {
+			return cols
+		}
+	}
+	t.Fatalf("signal %s is not in the report:\n%s", signal, report)
+	return nil
+}
+
+func TestRun(t *testing.T) {
+	base := flags{
+		proof:     testProofFile,
+		key:       testKeyFile,
+		proofType: "global_passport",
+		ageAbove:  -1,
+		eventID:   testEventID,
+		noRoot:    true,
+		timeout:   time.Second,
+	}
+
+	// the signals are valid, while the fake proof fails groth16
+	var out bytes.Buffer
+	f := base
+	f.citizenships = stringsFlag{"UKR"}
+	assert.Equal(t, exitInvalid, run(f, &out))
+	report := out.String()
+	assert.Equal(t, []string{"6", "citizenship", "5589842", "UKR", "ok"}, row(t, report, "citizenship"))
+	assert.Equal(t, []string{"9", "event_id", testEventID, "ok"}, row(t, report, "event_id"))
+	assert.Equal(t, []string{"19", "birth_date_upper_bound", "52983525093425", "2000-01-01", "ok"},
+		row(t, report, "birth_date_upper_bound"))
+	assert.Contains(t, report, "/proof  FAIL [groth16_failed]")
+	assert.Contains(t, report, "proof is INVALID")
+
+	out.Reset()
+	f = base
+	f.ageAbove = 18
+	f.citizenships = stringsFlag{"USA"}
+	f.eventID = "1"
+	assert.Equal(t, exitInvalid, run(f, &out))
+	report = out.String()
+	assert.Contains(t, row(t, report, "citizenship")[4], "FAIL [citizenship_not_allowed]")
+	assert.Contains(t, row(t, report, "event_id")[3], "FAIL [event_id_mismatch]")
+	assert.Contains(t, row(t, report, "birth_date_upper_bound")[4], "FAIL [age_too_low]")
+	assert.NotContains(t, report, "groth16_failed")
+
+	for name, f := range map[string]flags{
+		"missing key":      {proof: testProofFile, proofType: "global_passport", ageAbove: -1, noRoot: true},
+		"missing proof":    {proof: "testdata/missing.json", key: testKeyFile, proofType: "global_passport", ageAbove: -1, noRoot: true},
+		"bad proof type":   {proof: testProofFile, key: testKeyFile, proofType: "driver_license", ageAbove: -1, noRoot: true},
+		"root without rpc": {proof: testProofFile, key: testKeyFile, proofType: "global_passport", ageAbove: -1},
+	} {
+		out.Reset()
+		assert.Equal(t, exitInternal, run(f, &out), name)
+		assert.Contains(t, out.String(), "error:", name)
+	}
+}
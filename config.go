@@ -0,0 +1,150 @@
+package zkverifier_kit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rarimo/zkverifier-kit/root"
+	"gitlab.com/distributed_lab/figure/v3"
+	"gitlab.com/distributed_lab/kit/kv"
+)
+
+// policyConfig is the verifier policy block in config map, see NewVerifierFromConfig
+type policyConfig struct {
+	ProofType            string        `fig:"proof_type"`
+	VerificationKey      string        `fig:"verification_key,required"`
+	RootVerifier         string        `fig:"root_verifier"`
+	RootVerifierConfig   string        `fig:"root_verifier_config"`
+	AgeAbove             *int          `fig:"age_above"`
+	AgeBelow             *int          `fig:"age_below"`
+	Citizenships         []string      `fig:"citizenships"`
+	ExcludedCitizenships []string      `fig:"excluded_citizenships"`
+	EventID              string        `fig:"event_id"`
+	PartEventID          string        `fig:"participation_event_id"`
+	Selector             string        `fig:"selector"`
+	DocumentType         string        `fig:"document_type"`
+	IdentitiesCounter    *int64        `fig:"identities_counter"`
+	IdentitiesTimestamp  *int64        `fig:"identities_timestamp_limit"`
+	DocumentValidFor     time.Duration `fig:"document_valid_for"`
+	DateTolerance        int           `fig:"date_tolerance"`
+}
+
+// NewVerifierFromConfig creates Verifier from the policy block in config map
+// under the key. Example:
+//
+//	adult_ukr:
+//	  proof_type: global_passport # default, or georgian_passport, poll_participation
+//	  verification_key: ./key.json # required
+//	  root_verifier: poseidonsmt_root_verifier # root.VerifierType, optional
+//	  root_verifier_config: mainnet_root # config map key, root_verifier by default
+//	  age_above: 18
+//	  age_below: 65
+//	  citizenships: [UKR, EU]
+//	  excluded_citizenships: [RUS]
+//	  event_id: "3043..."
+//	  participation_event_id: "1234..." # poll_participation only
+//	  selector: "23073"
+//	  document_type: TD3
+//	  identities_counter: 1
+//	  identities_timestamp_limit: 1847321000
+//	  document_valid_for: 2160h
+//	  date_tolerance: 1
+//
+// Root verifier is created with root.NewVerifierFromConfig from the map under
+// root_verifier_config key, or under its type name when the key is omitted.
+// Invalid root verifier config is returned as error. The options are
+// applied after the config ones, e.g. WithLogger or WithObserver. When the
+// observer also implements root.Observer, e.g. metrics.Metrics, the root
+// verifier is wrapped with root.NewObservedVerifier to report its calls.
+func NewVerifierFromConfig(getter kv.Getter, key string, options ...VerifyOption) (*Verifier, error) {
+	raw, err := getter.GetStringMap(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s config map: %w", key, err)
+	}
+
+	var cfg policyConfig
+	if err = figure.Out(&cfg).From(raw).Please(); err != nil {
+		return nil, fmt.Errorf("failed to figure out %s: %w", key, err)
+	}
+
+	observer := mergeOptions(true, VerifyOptions{}, options...).observer
+	opts, err := cfg.options(getter, observer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s config: %w", key, err)
+	}
+
+	v, err := NewVerifier(nil, append(opts, options...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create verifier from %s config: %w", key, err)
+	}
+
+	return v, nil
+}
+
+func (c policyConfig) options(getter kv.Getter, observer Observer) ([]VerifyOption, error) {
+	typ := GlobalPassport
+	if c.ProofType != "" {
+		var err error
+		if typ, err = ParseProofType(c.ProofType); err != nil {
+			return nil, err
+		}
+	}
+
+	opts := []VerifyOption{
+		WithProofType(typ),
+		WithVerificationKeyFile(c.VerificationKey),
+		WithEventID(c.EventID),
+		WithPollParticipationEventID(c.PartEventID),
+		WithProofSelectorValue(c.Selector),
+		WithDocumentType(c.DocumentType),
+		WithDocumentValidFor(c.DocumentValidFor),
+		WithDateTolerance(c.DateTolerance),
+	}
+
+	if c.AgeAbove != nil {
+		opts = append(opts, WithAgeAbove(*c.AgeAbove))
+	}
+	if c.AgeBelow != nil {
+		opts = append(opts, WithAgeBelow(*c.AgeBelow))
+	}
+	if len(c.Citizenships) != 0 {
+		opts = append(opts, WithCitizenships(c.Citizenships...))
+	}
+	if len(c.ExcludedCitizenships) != 0 {
+		opts = append(opts, WithExcludedCitizenships(c.ExcludedCitizenships...))
+	}
+	if c.IdentitiesCounter != nil {
+		opts = append(opts, WithIdentitiesCounter(*c.IdentitiesCounter))
+	}
+	if c.IdentitiesTimestamp != nil {
+		opts = append(opts, WithIdentitiesCreationTimestampLimit(*c.IdentitiesTimestamp))
+	}
+
+	if c.RootVerifier == "" {
+		return opts, nil
+	}
+
+	rootType := root.VerifierType(c.RootVerifier)
+	switch rootType {
+	case root.PoseidonSMT, root.ProposalSMT:
+	default:
+		return nil, fmt.Errorf("unsupported root verifier type: %s", rootType)
+	}
+
+	rootKey := c.RootVerifierConfig
+	if rootKey == "" {
+		rootKey = c.RootVerifier
+	}
+
+	rv, err := root.NewVerifierFromConfig(getter, rootKey, rootType)
+	if err != nil {
+		return nil, err
+	}
+	if ro, ok := observer.(root.Observer); ok && rv != (root.DisabledVerifier{}) {
+		rv = root.NewObservedVerifier(rv, rootType, ro)
+	}
+	if typ == PollParticipation {
+		return append(opts, WithPollRootVerifier(rv)), nil
+	}
+	return append(opts, WithPassportRootVerifier(rv)), nil
+}
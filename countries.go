@@ -0,0 +1,33 @@
+package zkverifier_kit
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/rarimo/zkverifier-kit/country"
+)
+
+// EncodeCitizenshipMask builds the citizenship mask from the list of ISO 3166-1
+// alpha-3 country codes or group names from country package. The i-th bit of
+// the mask is set when the i-th country of country.All is present. Use it to
+// pass the excluded citizenships into the proof generation with the same value
+// as WithExcludedCitizenships expects.
+//
+// WithExcludedCitizenships reads the citizenship mask signal (22 of
+// GlobalPassport) with this layout: the bit number is the index of alpha-3 code
+// in the alphabetical list of 249 ISO 3166-1 countries, the least significant
+// bit is ABW, and a set bit means the citizenship is excluded. The layout is
+// defined by this package and is not confirmed against the circuit yet, so check
+// it with a real proof before relying on WithExcludedCitizenships.
+func EncodeCitizenshipMask(codes ...string) (*big.Int, error) {
+	mask := new(big.Int)
+	for _, code := range country.Expand(codes...) {
+		i, ok := country.Index(code)
+		if !ok {
+			return nil, fmt.Errorf("unknown alpha-3 country code %q", code)
+		}
+		mask.SetBit(mask, i, 1)
+	}
+
+	return mask, nil
+}
@@ -0,0 +1,55 @@
+package country
+
+import "slices"
+
+// Names of the supported country groups
+const (
+	EU       = "EU"
+	EEA      = "EEA"
+	Schengen = "SCHENGEN"
+	OECD     = "OECD"
+)
+
+var eu = []string{
+	"AUT", "BEL", "BGR", "CYP", "CZE", "DEU", "DNK", "ESP", "EST", "FIN", "FRA", "GRC", "HRV", "HUN",
+	"IRL", "ITA", "LTU", "LUX", "LVA", "MLT", "NLD", "POL", "PRT", "ROU", "SVK", "SVN", "SWE",
+}
+
+var groups = map[string][]string{
+	EU:  eu,
+	EEA: append(slices.Clone(eu), "ISL", "LIE", "NOR"),
+	Schengen: {
+		"AUT", "BEL", "BGR", "CHE", "CZE", "DEU", "DNK", "ESP", "EST", "FIN", "FRA", "GRC", "HRV", "HUN",
+		"ISL", "ITA", "LIE", "LTU", "LUX", "LVA", "MLT", "NLD", "NOR", "POL", "PRT", "ROU", "SVK", "SVN",
+		"SWE",
+	},
+	OECD: {
+		"AUS", "AUT", "BEL", "CAN", "CHE", "CHL", "COL", "CRI", "CZE", "DEU", "DNK", "ESP", "EST", "FIN",
+		"FRA", "GBR", "GRC", "HUN", "IRL", "ISL", "ISR", "ITA", "JPN", "KOR", "LTU", "LUX", "LVA", "MEX",
+		"NLD", "NOR", "NZL", "POL", "PRT", "SVK", "SVN", "SWE", "TUR", "USA",
+	},
+}
+
+// Group returns Alpha-3 codes of the group members. Group name is one of EU,
+// EEA, Schengen, OECD.
+func Group(name string) ([]string, bool) {
+	members, ok := groups[name]
+	return slices.Clone(members), ok
+}
+
+// Expand replaces group names with Alpha-3 codes of their members and removes
+// duplicates. Other values are kept as is, so you should validate them with
+// ByAlpha3 if needed.
+func Expand(codes ...string) []string {
+	expanded := make([]string, 0, len(codes))
+	for _, code := range codes {
+		if members, ok := groups[code]; ok {
+			expanded = append(expanded, members...)
+			continue
+		}
+		expanded = append(expanded, code)
+	}
+
+	slices.Sort(expanded)
+	return slices.Compact(expanded)
+}
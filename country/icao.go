@@ -0,0 +1,99 @@
+package country
+
+import (
+	"slices"
+	"strings"
+)
+
+// Special nationality categories of ICAO 9303 which are not ISO 3166-1
+// countries. They can be used in citizenship lists along with Alpha-3 codes.
+const (
+	// Stateless is a stateless person, as defined in the 1954 Convention
+	Stateless = "XXA"
+	// Refugee is a refugee, as defined in the 1951 Convention
+	Refugee = "XXB"
+	// RefugeeOther is a refugee other than defined by the 1951 Convention
+	RefugeeOther = "XXC"
+	// Unspecified is a person of unspecified nationality
+	Unspecified = "XXX"
+	// UNOfficial is an official of the United Nations organization
+	UNOfficial = "UNO"
+	// UNAgencyOfficial is an official of a specialized agency of the United Nations
+	UNAgencyOfficial = "UNA"
+	// UNKosovo is a resident of Kosovo to whom a travel document has been issued
+	// by the United Nations Interim Administration Mission in Kosovo
+	UNKosovo = "UNK"
+	// Kosovo is a citizen of the Republic of Kosovo
+	Kosovo = "RKS"
+	// EULaissezPasser is a holder of the European Union laissez-passer
+	EULaissezPasser = "EUE"
+)
+
+// ICAO 9303 codes of British nationalities other than British citizen, which is
+// "GBR". They are distinct nationalities with different rights, so they are
+// not converted to "GBR" by Normalize, use FoldBritish when it is intended.
+const (
+	// BritishOverseasTerritoriesCitizen is a British Overseas Territories Citizen
+	BritishOverseasTerritoriesCitizen = "GBD"
+	// BritishNationalOverseas is a British National (Overseas)
+	BritishNationalOverseas = "GBN"
+	// BritishOverseasCitizen is a British Overseas Citizen
+	BritishOverseasCitizen = "GBO"
+	// BritishProtectedPerson is a British Protected Person
+	BritishProtectedPerson = "GBP"
+	// BritishSubject is a British Subject
+	BritishSubject = "GBS"
+)
+
+var britishNationalities = []string{
+	BritishOverseasTerritoriesCitizen, BritishNationalOverseas, BritishOverseasCitizen, BritishProtectedPerson, BritishSubject,
+}
+
+var specialCategories = []string{
+	EULaissezPasser, Kosovo, UNAgencyOfficial, UNKosovo, UNOfficial, Stateless, Refugee, RefugeeOther, Unspecified,
+}
+
+// icaoToISO maps ICAO 9303 nationality codes, which are not equal to ISO 3166-1
+// alpha-3, to the latter
+var icaoToISO = map[string]string{
+	"D": "DEU", // Germany
+}
+
+// IsSpecialCategory checks whether the code is one of ICAO 9303 special
+// nationality categories, e.g. Stateless
+func IsSpecialCategory(code string) bool {
+	return slices.Contains(specialCategories, code)
+}
+
+// IsBritishNationality checks whether the code is one of ICAO 9303 British
+// nationalities other than British citizen, e.g. BritishNationalOverseas
+func IsBritishNationality(code string) bool {
+	return slices.Contains(britishNationalities, code)
+}
+
+// FoldBritish returns "GBR" for British nationality codes, e.g. "GBN", and the
+// code itself otherwise
+func FoldBritish(code string) string {
+	if IsBritishNationality(code) {
+		return "GBR"
+	}
+	return code
+}
+
+// Normalize converts ICAO 9303 nationality code from the document MRZ to the
+// canonical form: ISO 3166-1 alpha-3 code for countries (e.g. "D<<" becomes
+// "DEU"), British nationality code (e.g. BritishNationalOverseas) or special
+// category code (e.g. Stateless). The second return value is false when the
+// code is unknown, then the trimmed code is returned.
+func Normalize(code string) (string, bool) {
+	code = strings.TrimRight(code, "< ")
+	if iso, ok := icaoToISO[code]; ok {
+		return iso, true
+	}
+
+	if _, ok := ByAlpha3(code); ok || IsSpecialCategory(code) || IsBritishNationality(code) {
+		return code, true
+	}
+
+	return code, false
+}
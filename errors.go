@@ -0,0 +1,345 @@
+package zkverifier_kit
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	val "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// ErrorCode is a stable machine-readable code of verification failure. Codes
+// never change, unlike error messages, so use them to map the failures to UI.
+type ErrorCode string
+
+const (
+	CodeProofInvalid              ErrorCode = "proof_invalid"
+	CodeGroth16Failed             ErrorCode = "groth16_failed"
+	CodeNullifierInvalid          ErrorCode = "nullifier_invalid"
+	CodeRootInvalid               ErrorCode = "root_invalid"
+	CodeSelectorMismatch          ErrorCode = "selector_mismatch"
+	CodeEventIDMismatch           ErrorCode = "event_id_mismatch"
+	CodeEventDataMismatch         ErrorCode = "event_data_mismatch"
+	CodeAgeTooLow                 ErrorCode = "age_too_low"
+	CodeAgeTooHigh                ErrorCode = "age_too_high"
+	CodeCitizenshipNotAllowed     ErrorCode = "citizenship_not_allowed"
+	CodeCitizenshipNotExcluded    ErrorCode = "citizenship_not_excluded"
+	CodeDocumentExpired           ErrorCode = "document_expired"
+	CodeDocumentTypeNotAllowed    ErrorCode = "document_type_not_allowed"
+	CodeCurrentDateInvalid        ErrorCode = "current_date_invalid"
+	CodePersonalNumberNotEligible ErrorCode = "personal_number_not_eligible"
+	CodeIdentityLimitExceeded     ErrorCode = "identity_limit_exceeded"
+	CodeCustomCheckFailed         ErrorCode = "custom_check_failed"
+	// CodeOptionsInvalid is set by RenderJSONAPI and RenderProblem for
+	// ErrInvalidOptions, e.g. invalid WithRarimoAddress from the request
+	CodeOptionsInvalid ErrorCode = "options_invalid"
+	// CodeNullifierReused is never returned by the kit, because nullifiers are
+	// stored by the application. Use it in WithCustomCheck or on your side.
+	CodeNullifierReused ErrorCode = "nullifier_reused"
+)
+
+// Sentinel errors for each ErrorCode. The errors returned from
+// Verifier.VerifyProof are matched with errors.Is by the code, e.g.
+//
+//	errs := err.(validation.Errors)
+//	if errors.Is(errs["pub_signals/birth_date_upper_bound"], ErrAgeTooLow) {}
+var (
+	ErrProofInvalid           = &Error{Code: CodeProofInvalid}
+	ErrGroth16Failed          = &Error{Code: CodeGroth16Failed}
+	ErrNullifierInvalid       = &Error{Code: CodeNullifierInvalid}
+	ErrRootInvalid            = &Error{Code: CodeRootInvalid}
+	ErrSelectorMismatch       = &Error{Code: CodeSelectorMismatch}
+	ErrEventIDMismatch        = &Error{Code: CodeEventIDMismatch}
+	ErrEventDataMismatch      = &Error{Code: CodeEventDataMismatch}
+	ErrAgeTooLow              = &Error{Code: CodeAgeTooLow}
+	ErrAgeTooHigh             = &Error{Code: CodeAgeTooHigh}
+	ErrCitizenshipNotAllowed  = &Error{Code: CodeCitizenshipNotAllowed}
+	ErrCitizenshipNotExcluded = &Error{Code: CodeCitizenshipNotExcluded}
+	ErrDocumentExpired        = &Error{Code: CodeDocumentExpired}
+	ErrDocumentTypeNotAllowed = &Error{Code: CodeDocumentTypeNotAllowed}
+	ErrCurrentDateInvalid     = &Error{Code: CodeCurrentDateInvalid}
+	ErrIdentityLimitExceeded  = &Error{Code: CodeIdentityLimitExceeded}
+	// ErrPersonalNumberNotEligible shows that PersonalNumberHash of
+	// GeorgianPassport proof is not allowed by personal number options
+	ErrPersonalNumberNotEligible = &Error{
+		Code: CodePersonalNumberNotEligible,
+		Err:  errors.New("personal number hash is not eligible"),
+	}
+	ErrCustomCheckFailed = &Error{Code: CodeCustomCheckFailed}
+	ErrNullifierReused   = &Error{Code: CodeNullifierReused}
+)
+
+// keyCodes maps validation.Errors keys to the codes. Keys of custom rules are
+// not here, so they get CodeCustomCheckFailed, unless the error is already
+// *Error.
+var keyCodes = map[string]ErrorCode{
+	"zk_proof/proof":                           CodeProofInvalid,
+	"zk_proof/pub_signals":                     CodeProofInvalid,
+	"/proof":                                   CodeGroth16Failed,
+	"pub_signals/nullifier":                    CodeNullifierInvalid,
+	"pub_signals/id_state_root":                CodeRootInvalid,
+	"nullifiers_tree_root":                     CodeRootInvalid,
+	"pub_signals/selector":                     CodeSelectorMismatch,
+	"pub_signals/event_id":                     CodeEventIDMismatch,
+	"challenged_event_id":                      CodeEventIDMismatch,
+	"participation_event_id":                   CodeEventIDMismatch,
+	"pub_signals/event_data":                   CodeEventDataMismatch,
+	"pub_signals/birth_date_upper_bound":       CodeAgeTooLow,
+	"pub_signals/birth_date_lower_bound":       CodeAgeTooHigh,
+	"pub_signals/citizenship":                  CodeCitizenshipNotAllowed,
+	"pub_signals/citizenship_mask":             CodeCitizenshipNotExcluded,
+	"pub_signals/expiration_date":              CodeDocumentExpired,
+	"pub_signals/expiration_date_lower_bound":  CodeDocumentExpired,
+	"pub_signals/document_type":                CodeDocumentTypeNotAllowed,
+	"pub_signals/current_date":                 CodeCurrentDateInvalid,
+	"pub_signals/personal_number_hash":         CodePersonalNumberNotEligible,
+	"pub_signals/identity_counter_upper_bound": CodeIdentityLimitExceeded,
+	"pub_signals/timestamp_upper_bound":        CodeIdentityLimitExceeded,
+}
+
+var codeTitles = map[ErrorCode]string{
+	CodeProofInvalid:              "Proof is malformed",
+	CodeGroth16Failed:             "Proof verification failed",
+	CodeNullifierInvalid:          "Nullifier is invalid",
+	CodeRootInvalid:               "Identity state is unknown or outdated",
+	CodeSelectorMismatch:          "Proof selector does not match",
+	CodeEventIDMismatch:           "Event ID does not match",
+	CodeEventDataMismatch:         "Event data does not match",
+	CodeAgeTooLow:                 "Age is too low",
+	CodeAgeTooHigh:                "Age is too high",
+	CodeCitizenshipNotAllowed:     "Citizenship is not allowed",
+	CodeCitizenshipNotExcluded:    "Citizenship is not proven to be allowed",
+	CodeDocumentExpired:           "Document is expired or expires too soon",
+	CodeDocumentTypeNotAllowed:    "Document type is not allowed",
+	CodeCurrentDateInvalid:        "Proof date is invalid",
+	CodePersonalNumberNotEligible: "Person is not eligible",
+	CodeIdentityLimitExceeded:     "Identity was reissued too many times or too late",
+	CodeCustomCheckFailed:         "Proof is not eligible",
+	CodeNullifierReused:           "Proof was already used",
+	CodeOptionsInvalid:            "Verification parameters are invalid",
+}
+
+// Error is a verification failure with stable code. It wraps the original
+// error of the validation rule and has the same message.
+type Error struct {
+	Code ErrorCode
+	Err  error
+	// Params are the expected values of the failed check, which are useful to
+	// show to the user, e.g. "age" for CodeAgeTooLow. See ParamAge and others.
+	Params map[string]any
+}
+
+// Keys of Error.Params
+const (
+	// ParamAge is the required age for CodeAgeTooLow and CodeAgeTooHigh
+	ParamAge = "age"
+	// ParamCountries is the list of Alpha-3 codes for CodeCitizenshipNotAllowed
+	// and CodeCitizenshipNotExcluded
+	ParamCountries = "countries"
+	// ParamDays is the number of days the document must be valid for after
+	// verification for CodeDocumentExpired, zero by default
+	ParamDays = "days"
+)
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return string(e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches any *Error with the same code, so sentinel errors work with errors.Is
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Title returns short human-readable description of the error code
+func (c ErrorCode) Title() string {
+	if title, ok := codeTitles[c]; ok {
+		return title
+	}
+	return string(c)
+}
+
+// ErrorCodeOf extracts the code from the error, returning empty string if
+// there is no code, e.g. on internal errors.
+func ErrorCodeOf(err error) ErrorCode {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
+
+// withCodes attaches codes to the entries of validation errors, other errors
+// are returned as is. Params are set from the options, when they are provided.
+func withCodes(err error, opts *VerifyOptions) error {
+	var errs val.Errors
+	if !errors.As(err, &errs) {
+		return err
+	}
+
+	coded := make(val.Errors, len(errs))
+	for key, e := range errs {
+		if e == nil || ErrorCodeOf(e) != "" {
+			coded[key] = e
+			continue
+		}
+
+		code, ok := keyCodes[key]
+		if !ok {
+			code = CodeCustomCheckFailed
+		}
+		var params map[string]any
+		if opts != nil {
+			params = opts.errorParams(code)
+		}
+		coded[key] = &Error{Code: code, Err: e, Params: params}
+	}
+
+	return coded
+}
+
+func (o *VerifyOptions) errorParams(code ErrorCode) map[string]any {
+	switch code {
+	case CodeAgeTooLow:
+		return map[string]any{ParamAge: o.age}
+	case CodeAgeTooHigh:
+		return map[string]any{ParamAge: o.maxAge}
+	case CodeCitizenshipNotAllowed:
+		countries := make([]string, len(o.citizenships))
+		for i, ctz := range o.citizenships {
+			countries[i] = ctz.(string)
+		}
+		return map[string]any{ParamCountries: countries}
+	case CodeCitizenshipNotExcluded:
+		return map[string]any{ParamCountries: o.excludedCitizenships}
+	case CodeDocumentExpired:
+		return map[string]any{ParamDays: int(o.documentValidFor / (24 * time.Hour))}
+	default:
+		return nil
+	}
+}
+
+// JSONAPIError is an error object of JSON:API specification
+type JSONAPIError struct {
+	Status string            `json:"status"`
+	Code   ErrorCode         `json:"code,omitempty"`
+	Title  string            `json:"title"`
+	Detail string            `json:"detail,omitempty"`
+	Source *JSONAPIErrSource `json:"source,omitempty"`
+}
+
+// JSONAPIErrSource points to the invalid field of the request
+type JSONAPIErrSource struct {
+	Pointer string `json:"pointer"`
+}
+
+// Problem is a problem details document of RFC 7807 with the list of
+// verification failures in the "errors" extension member
+type Problem struct {
+	Type   string         `json:"type"`
+	Title  string         `json:"title"`
+	Status int            `json:"status"`
+	Detail string         `json:"detail,omitempty"`
+	Errors []ProblemError `json:"errors,omitempty"`
+}
+
+// ProblemError is a single verification failure in Problem
+type ProblemError struct {
+	Code    ErrorCode `json:"code"`
+	Title   string    `json:"title"`
+	Detail  string    `json:"detail"`
+	Pointer string    `json:"pointer"`
+}
+
+// RenderJSONAPI converts the error returned from Verifier.VerifyProof to JSON:API
+// error objects. Validation errors are rendered one per field with 400 status,
+// sorted by field. ErrInvalidOptions is a client error too, it is rendered
+// with 400 status and CodeOptionsInvalid under "options" field. Other errors
+// are considered internal and are rendered as a single object with 500 status
+// and without details.
+func RenderJSONAPI(err error) []JSONAPIError {
+	errs, ok := validationErrors(err)
+	if !ok {
+		return []JSONAPIError{{
+			Status: strconv.Itoa(http.StatusInternalServerError),
+			Title:  http.StatusText(http.StatusInternalServerError),
+		}}
+	}
+
+	res := make([]JSONAPIError, 0, len(errs))
+	for _, key := range sortedKeys(errs) {
+		code := ErrorCodeOf(errs[key])
+		res = append(res, JSONAPIError{
+			Status: strconv.Itoa(http.StatusBadRequest),
+			Code:   code,
+			Title:  code.Title(),
+			Detail: errs[key].Error(),
+			Source: &JSONAPIErrSource{Pointer: "/" + key},
+		})
+	}
+
+	return res
+}
+
+// RenderProblem converts the error returned from Verifier.VerifyProof to RFC
+// 7807 problem details in the same way as RenderJSONAPI.
+func RenderProblem(err error) Problem {
+	errs, ok := validationErrors(err)
+	if !ok {
+		return Problem{
+			Type:   "about:blank",
+			Title:  http.StatusText(http.StatusInternalServerError),
+			Status: http.StatusInternalServerError,
+		}
+	}
+
+	res := Problem{
+		Type:   "about:blank",
+		Title:  "Proof verification failed",
+		Status: http.StatusBadRequest,
+		Errors: make([]ProblemError, 0, len(errs)),
+	}
+	for _, key := range sortedKeys(errs) {
+		code := ErrorCodeOf(errs[key])
+		res.Errors = append(res.Errors, ProblemError{
+			Code:    code,
+			Title:   code.Title(),
+			Detail:  errs[key].Error(),
+			Pointer: "/" + key,
+		})
+	}
+
+	return res
+}
+
+func validationErrors(err error) (val.Errors, bool) {
+	if errors.Is(err, ErrInvalidOptions) {
+		return val.Errors{"options": &Error{Code: CodeOptionsInvalid, Err: err}}, true
+	}
+
+	var errs val.Errors
+	if !errors.As(err, &errs) {
+		return nil, false
+	}
+	return withCodes(errs, nil).(val.Errors), true
+}
+
+func sortedKeys(errs val.Errors) []string {
+	keys := make([]string, 0, len(errs))
+	for key, e := range errs {
+		if e != nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
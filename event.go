@@ -0,0 +1,101 @@
+package zkverifier_kit
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/cosmos/btcutil/bech32"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/iden3/go-iden3-crypto/constants"
+)
+
+// DeriveEventID derives deterministic event ID from the namespace, e.g. the
+// service or environment name, and the event name. The result is a decimal
+// string, suitable for WithEventID and proof generation. See
+// DeriveEventIDFromPayload for the construction.
+func DeriveEventID(namespace, name string) string {
+	return DeriveEventIDFromPayload(namespace, []byte(name))
+}
+
+// DeriveEventIDFromPayload derives deterministic event ID from the namespace
+// and arbitrary payload as keccak256(keccak256(namespace) || payload) reduced
+// modulo BN254 scalar field order, so the result always fits the field.
+// Hashing the namespace separately makes the boundary between the namespace and
+// payload unambiguous. The same value in Solidity:
+//
+//	uint256(keccak256(abi.encodePacked(keccak256(bytes(namespace)), payload))) % Q
+//
+// and with ethers.js:
+//
+//	BigInt(keccak256(concat([keccak256(toUtf8Bytes(namespace)), payload]))) % Q
+//
+// The construction is defined by this package: it is not confirmed to match the
+// event IDs of Rarimo apps, so keep WithEventID with the value of the app when
+// the proofs are generated for its events.
+func DeriveEventIDFromPayload(namespace string, payload []byte) string {
+	hash := crypto.Keccak256(crypto.Keccak256([]byte(namespace)), payload)
+	return new(big.Int).Mod(new(big.Int).SetBytes(hash), constants.Q).String()
+}
+
+// rarimoAddressPrefix is the human-readable part of Rarimo bech32 addresses
+const rarimoAddressPrefix = "rarimo"
+
+// EncodeEventDataRarimoAddress validates Rarimo bech32 address (rarimo1...) and
+// encodes it for EventData public signal: the data part of the address is
+// taken as is, in 5-bit groups, one byte for each.
+func EncodeEventDataRarimoAddress(address string) ([]byte, error) {
+	hrp, data, err := bech32.Decode(address, bech32.MaxLengthBIP173)
+	if err != nil {
+		return nil, fmt.Errorf("decode bech32 address: %w", err)
+	}
+
+	if hrp != rarimoAddressPrefix {
+		return nil, fmt.Errorf("invalid address prefix %q, expected %q", hrp, rarimoAddressPrefix)
+	}
+
+	if _, err = bech32.ConvertBits(data, 5, 8, false); err != nil {
+		return nil, fmt.Errorf("invalid address data: %w", err)
+	}
+
+	return data, nil
+}
+
+// maxEventDataLen is the maximal length of the event data to fit the field
+const maxEventDataLen = 31
+
+// EncodeEventDataAddress encodes Ethereum address for EventData public signal
+func EncodeEventDataAddress(addr common.Address) []byte {
+	return addr.Bytes()
+}
+
+// EncodeEventDataUUID encodes UUID for EventData public signal. Any UUID type
+// based on [16]byte is accepted, e.g. from github.com/google/uuid.
+func EncodeEventDataUUID(id [16]byte) []byte {
+	return id[:]
+}
+
+// EncodeEventDataUint256 encodes non-negative integer for EventData public
+// signal. The value must fit in 31 bytes.
+func EncodeEventDataUint256(v *big.Int) ([]byte, error) {
+	if v.Sign() < 0 || v.BitLen() > maxEventDataLen*8 {
+		return nil, fmt.Errorf("value %s does not fit %d bytes", v, maxEventDataLen)
+	}
+	return v.Bytes(), nil
+}
+
+// EncodeEventDataString encodes short string for EventData public signal. The
+// string must be up to 31 bytes long.
+func EncodeEventDataString(s string) ([]byte, error) {
+	if len(s) > maxEventDataLen {
+		return nil, fmt.Errorf("string length %d exceeds %d bytes", len(s), maxEventDataLen)
+	}
+	return []byte(s), nil
+}
+
+// EncodeEventDataHash commits to the arbitrary payload for EventData public
+// signal: keccak256 hash of the payload is reduced to its last 31 bytes.
+func EncodeEventDataHash(payload []byte) []byte {
+	hash := crypto.Keccak256(payload)
+	return hash[len(hash)-maxEventDataLen:]
+}
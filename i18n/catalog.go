@@ -0,0 +1,51 @@
+package i18n
+
+import kit "github.com/rarimo/zkverifier-kit"
+
+// Default is the catalog with English and Ukrainian messages for all the codes
+var Default = Catalog{
+	"en": {
+		CodeInternal:                      "Something went wrong, please try again later",
+		kit.CodeProofInvalid:              "The proof is malformed, please generate it again",
+		kit.CodeGroth16Failed:             "The proof could not be verified, please generate it again",
+		kit.CodeNullifierInvalid:          "The proof is malformed, please generate it again",
+		kit.CodeRootInvalid:               "Your identity state is outdated, please generate the proof again",
+		kit.CodeSelectorMismatch:          "The proof was generated for other requirements",
+		kit.CodeEventIDMismatch:           "The proof was generated for another event",
+		kit.CodeEventDataMismatch:         "The proof was generated for other data",
+		kit.CodeAgeTooLow:                 "You must be at least {age} years old",
+		kit.CodeAgeTooHigh:                "You must be younger than {age} years",
+		kit.CodeCitizenshipNotAllowed:     "Your citizenship is not eligible, allowed countries: {countries}",
+		kit.CodeCitizenshipNotExcluded:    "The proof doesn't confirm that your citizenship is not one of: {countries}",
+		kit.CodeDocumentExpired:           "Your document is expired or expires too soon",
+		kit.CodeDocumentTypeNotAllowed:    "Your document type is not supported",
+		kit.CodeCurrentDateInvalid:        "The proof date is invalid, please check the date on your device",
+		kit.CodePersonalNumberNotEligible: "You are not eligible for this programme",
+		kit.CodeIdentityLimitExceeded:     "Your identity was reissued too many times or too recently",
+		kit.CodeCustomCheckFailed:         "The proof doesn't meet the requirements",
+		kit.CodeNullifierReused:           "This proof has already been used",
+		kit.CodeOptionsInvalid:            "The request parameters are invalid",
+	},
+	"uk": {
+		CodeInternal:                      "Щось пішло не так, спробуйте пізніше",
+		kit.CodeProofInvalid:              "Доказ має неправильний формат, згенеруйте його ще раз",
+		kit.CodeGroth16Failed:             "Не вдалося перевірити доказ, згенеруйте його ще раз",
+		kit.CodeNullifierInvalid:          "Доказ має неправильний формат, згенеруйте його ще раз",
+		kit.CodeRootInvalid:               "Стан вашої ідентичності застарів, згенеруйте доказ ще раз",
+		kit.CodeSelectorMismatch:          "Доказ згенеровано для інших умов",
+		kit.CodeEventIDMismatch:           "Доказ згенеровано для іншої події",
+		kit.CodeEventDataMismatch:         "Доказ згенеровано для інших даних",
+		kit.CodeAgeTooLow:                 "Мінімальний вік для участі: {age}",
+		kit.CodeAgeTooHigh:                "Ваш вік має бути меншим за {age}",
+		kit.CodeCitizenshipNotAllowed:     "Ваше громадянство не відповідає умовам, дозволені країни: {countries}",
+		kit.CodeCitizenshipNotExcluded:    "Доказ не підтверджує, що ваше громадянство не належить до списку: {countries}",
+		kit.CodeDocumentExpired:           "Термін дії вашого документа закінчився або скоро закінчиться",
+		kit.CodeDocumentTypeNotAllowed:    "Тип вашого документа не підтримується",
+		kit.CodeCurrentDateInvalid:        "Дата доказу недійсна, перевірте дату на вашому пристрої",
+		kit.CodePersonalNumberNotEligible: "Ви не можете взяти участь у цій програмі",
+		kit.CodeIdentityLimitExceeded:     "Вашу ідентичність перевипускали забагато разів або нещодавно",
+		kit.CodeCustomCheckFailed:         "Доказ не відповідає умовам",
+		kit.CodeNullifierReused:           "Цей доказ уже використано",
+		kit.CodeOptionsInvalid:            "Параметри запиту недійсні",
+	},
+}
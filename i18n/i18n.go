@@ -0,0 +1,101 @@
+// Package i18n converts verification failures of the kit into end-user
+// messages in different languages.
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	val "github.com/go-ozzo/ozzo-validation/v4"
+	kit "github.com/rarimo/zkverifier-kit"
+)
+
+// CodeInternal is used for the errors without kit.ErrorCode, i.e. internal
+// errors of the verifier
+const CodeInternal kit.ErrorCode = "internal_error"
+
+// DefaultLanguage is used when the requested language is not in the Catalog
+const DefaultLanguage = "en"
+
+// Translator converts the error code with params (see kit.Error) into the
+// message for the given language
+type Translator interface {
+	Translate(lang string, code kit.ErrorCode, params map[string]any) string
+}
+
+// Catalog is a Translator with message templates by language and error code.
+// Templates may contain params in braces, e.g. "{age}", which are replaced with
+// the values of kit.Error.Params. Lists are joined with comma.
+//
+// Language is a BCP 47 tag, and only its primary subtag is used when the full
+// tag is not found, e.g. "uk-UA" falls back to "uk". Then DefaultLanguage is
+// used, and then the title of the code.
+type Catalog map[string]map[kit.ErrorCode]string
+
+// Translate implements Translator
+func (c Catalog) Translate(lang string, code kit.ErrorCode, params map[string]any) string {
+	tmpl, ok := c.template(lang, code)
+	if !ok {
+		return code.Title()
+	}
+
+	pairs := make([]string, 0, len(params)*2)
+	for key, value := range params {
+		pairs = append(pairs, "{"+key+"}", format(value))
+	}
+
+	return strings.NewReplacer(pairs...).Replace(tmpl)
+}
+
+func (c Catalog) template(lang string, code kit.ErrorCode) (string, bool) {
+	primary, _, _ := strings.Cut(lang, "-")
+	for _, l := range []string{lang, strings.ToLower(primary), DefaultLanguage} {
+		if tmpl, ok := c[l][code]; ok {
+			return tmpl, true
+		}
+	}
+	return "", false
+}
+
+func format(value any) string {
+	if list, ok := value.([]string); ok {
+		return strings.Join(list, ", ")
+	}
+	return fmt.Sprint(value)
+}
+
+// Messages translates the error returned from kit.Verifier.VerifyProof. For
+// validation errors, the result maps the keys of validation.Errors to the
+// messages. Other errors are considered internal and are translated with
+// CodeInternal under empty key.
+func Messages(t Translator, lang string, err error) map[string]string {
+	if err == nil {
+		return nil
+	}
+
+	var errs val.Errors
+	if !errors.As(err, &errs) {
+		return map[string]string{"": t.Translate(lang, CodeInternal, nil)}
+	}
+
+	res := make(map[string]string, len(errs))
+	for key, e := range errs {
+		if e == nil {
+			continue
+		}
+
+		var (
+			kerr   *kit.Error
+			code   = kit.CodeCustomCheckFailed
+			params map[string]any
+		)
+		if errors.As(e, &kerr) {
+			code, params = kerr.Code, kerr.Params
+		}
+
+		res[key] = t.Translate(lang, code, params)
+	}
+
+	return res
+}
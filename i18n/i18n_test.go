@@ -0,0 +1,69 @@
+package i18n
+
+import (
+	"errors"
+	"testing"
+
+	val "github.com/go-ozzo/ozzo-validation/v4"
+	kit "github.com/rarimo/zkverifier-kit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessages(t *testing.T) {
+	err := val.Errors{
+		"pub_signals/birth_date_upper_bound": &kit.Error{
+			Code:   kit.CodeAgeTooLow,
+			Err:    errors.New("dates are not equal"),
+			Params: map[string]any{kit.ParamAge: 18},
+		},
+		"pub_signals/citizenship": &kit.Error{
+			Code:   kit.CodeCitizenshipNotAllowed,
+			Err:    errors.New("must be a valid value"),
+			Params: map[string]any{kit.ParamCountries: []string{"POL", "UKR"}},
+		},
+		"pub_signals/custom":   errors.New("forbidden"),
+		"pub_signals/selector": nil,
+	}
+
+	testCases := []struct {
+		name string
+		lang string
+		err  error
+		want map[string]string
+	}{
+		{
+			name: "English",
+			lang: "en",
+			err:  err,
+			want: map[string]string{
+				"pub_signals/birth_date_upper_bound": "You must be at least 18 years old",
+				"pub_signals/citizenship":            "Your citizenship is not eligible, allowed countries: POL, UKR",
+				"pub_signals/custom":                 "The proof doesn't meet the requirements",
+			},
+		},
+		{
+			name: "Ukrainian with region",
+			lang: "uk-UA",
+			err:  err,
+			want: map[string]string{
+				"pub_signals/birth_date_upper_bound": "Мінімальний вік для участі: 18",
+				"pub_signals/citizenship":            "Ваше громадянство не відповідає умовам, дозволені країни: POL, UKR",
+				"pub_signals/custom":                 "Доказ не відповідає умовам",
+			},
+		},
+		{
+			name: "Unknown language",
+			lang: "de",
+			err:  errors.New("rpc is down"),
+			want: map[string]string{"": "Something went wrong, please try again later"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Messages(Default, tc.lang, tc.err))
+		})
+	}
+
+	assert.Equal(t, "unknown_code", Default.Translate("en", "unknown_code", nil))
+}
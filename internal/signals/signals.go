@@ -0,0 +1,75 @@
+// Package signals decodes public signals for human-readable output of the
+// commands
+package signals
+
+import (
+	"math/big"
+	"sort"
+
+	kit "github.com/rarimo/zkverifier-kit"
+	"github.com/rarimo/zkverifier-kit/country"
+	"github.com/rarimo/zkverifier-kit/zkdate"
+)
+
+// Signal is a public signal with its index in the proof and decoded value for
+// dates and citizenship
+type Signal struct {
+	Name    string `json:"-"`
+	Index   int    `json:"index"`
+	Value   string `json:"value"`
+	Decoded string `json:"decoded,omitempty"`
+}
+
+var dateFields = map[string]zkdate.Field{
+	kit.BirthDate.String():                zkdate.Birth,
+	kit.BirthdateLowerBound.String():      zkdate.Birth,
+	kit.BirthdateUpperBound.String():      zkdate.Birth,
+	kit.ExpirationDate.String():           zkdate.Expiry,
+	kit.ExpirationDateLowerBound.String(): zkdate.Expiry,
+	kit.CurrentDate.String():              zkdate.Expiry,
+}
+
+// Decode returns signals present in the proof sorted by index
+func Decode(signals kit.PubSignalGetter) []Signal {
+	var res []Signal
+
+	for id, i := range kit.Indexes(signals.ProofType) {
+		if i >= len(signals.Signals) {
+			continue
+		}
+
+		s := Signal{Name: id.String(), Index: i, Value: signals.Signals[i]}
+		if field, ok := dateFields[s.Name]; ok {
+			if date, err := zkdate.Decode(s.Value, field); err == nil {
+				s.Decoded = date.Format("2006-01-02")
+			}
+		} else if s.Name == kit.Citizenship.String() {
+			s.Decoded = citizenship(s.Value)
+		}
+
+		res = append(res, s)
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Index < res[j].Index })
+	return res
+}
+
+// Map returns decoded signals by names
+func Map(signals kit.PubSignalGetter) map[string]Signal {
+	decoded := Decode(signals)
+	res := make(map[string]Signal, len(decoded))
+	for _, s := range decoded {
+		res[s.Name] = s
+	}
+	return res
+}
+
+func citizenship(value string) string {
+	b, ok := new(big.Int).SetString(value, 10)
+	if !ok || b.Sign() == 0 {
+		return ""
+	}
+
+	code, _ := country.Normalize(string(b.Bytes()))
+	return code
+}
@@ -0,0 +1,144 @@
+package zkverifier_kit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	val "github.com/go-ozzo/ozzo-validation/v4"
+	zkptypes "github.com/iden3/go-rapidsnark/types"
+	"github.com/rarimo/zkverifier-kit/logging"
+	"github.com/rarimo/zkverifier-kit/root"
+)
+
+func (o *VerifyOptions) log(level logging.Level, msg string, fields logging.Fields) {
+	o.logger.Log(level, msg, fields)
+}
+
+// logConfig logs the options resolved in NewVerifier
+func (o *VerifyOptions) logConfig() {
+	fields := logging.Fields{
+		"proof_type":            o.proofType.String(),
+		"passport_root":         string(root.TypeOf(o.passportVerifier)),
+		"date_tolerance":        o.dateTolerance,
+		"document_valid_for":    o.documentValidFor.String(),
+		"custom_checks":         len(o.customChecks) + len(o.signalRules),
+		"key_file":              o.verificationKeyFile,
+		"event_id":              o.eventID,
+		"selector":              o.proofSelectorValue,
+		"citizenships":          o.citizenships,
+		"excluded_citizenships": o.excludedCitizenships,
+	}
+	if len(o.profiles) != 0 {
+		fields["profiles"] = len(o.profiles)
+	}
+	if o.voteVerifier != nil {
+		fields["poll_root"] = string(root.TypeOf(o.voteVerifier))
+	}
+	if o.age != -1 {
+		fields["age_above"] = o.age
+	}
+	if o.maxAge != -1 {
+		fields["age_below"] = o.maxAge
+	}
+	if o.maxIdentitiesCount != -1 {
+		fields["identities_counter"] = o.maxIdentitiesCount
+	}
+	if !o.maxIdentityCreationTimestamp.IsZero() {
+		fields["identities_timestamp_limit"] = o.maxIdentityCreationTimestamp.Unix()
+	}
+
+	o.log(logging.LevelDebug, "verifier configured", fields)
+}
+
+// logRootCall logs the result of root verification, invalid root is not an
+// error of the call
+func (o *VerifyOptions) logRootCall(v root.Verifier, d time.Duration, err error) {
+	fields := logging.Fields{
+		"root_verifier": string(root.TypeOf(v)),
+		"duration":      d.String(),
+		"valid":         err == nil,
+	}
+
+	if err != nil && !errors.Is(err, root.ErrInvalidRoot) {
+		fields["error_kind"] = rootErrorKind(err)
+		o.log(logging.LevelWarn, "root verification call failed", fields)
+		return
+	}
+
+	o.log(logging.LevelDebug, "root verified", fields)
+}
+
+// rootErrorKind classifies the failure of root verification call. The error
+// itself is not logged, because it may contain RPC URL with API key.
+func rootErrorKind(err error) string {
+	var (
+		httpErr rpc.HTTPError
+		rpcErr  rpc.Error
+		netErr  net.Error
+	)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.As(err, &httpErr):
+		return fmt.Sprintf("http_%d", httpErr.StatusCode)
+	case errors.As(err, &rpcErr):
+		return fmt.Sprintf("rpc_%d", rpcErr.ErrorCode())
+	case errors.As(err, &netErr):
+		return "network"
+	default:
+		return "internal"
+	}
+}
+
+// redactError removes the request URL from the error message, because RPC URL
+// may contain API key
+func redactError(err error) error {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) || urlErr.URL == "" {
+		return err
+	}
+	return errors.New(strings.ReplaceAll(err.Error(), urlErr.URL, "[redacted]"))
+}
+
+// logDecision logs the result of VerifyProof. Personal data is redacted by the
+// logger, see WithLogger.
+func (o *VerifyOptions) logDecision(proof zkptypes.ZKProof, err error) {
+	signals := PubSignalGetter{ProofType: o.proofType, Signals: proof.PubSignals}
+	fields := logging.Fields{
+		"proof_type":                  o.proofType.String(),
+		Nullifier.String():            signals.Get(Nullifier),
+		EventID.String():              signals.Get(EventID),
+		ParticipationEventID.String(): signals.Get(ParticipationEventID),
+	}
+	if o.profile != "" {
+		fields["profile"] = o.profile
+	}
+	if o.proofType == GeorgianPassport {
+		fields[PersonalNumberHash.String()] = signals.Get(PersonalNumberHash)
+	}
+
+	var errs val.Errors
+	switch {
+	case err == nil:
+		o.log(logging.LevelInfo, "proof accepted", fields)
+	case errors.As(err, &errs):
+		reasons := make(map[string]string, len(errs))
+		for key, e := range errs {
+			reasons[key] = e.Error()
+		}
+		fields["reasons"] = reasons
+		fields["error_codes"] = errorCodes(errs)
+		o.log(logging.LevelInfo, "proof rejected", fields)
+	default:
+		fields["error"] = redactError(err)
+		o.log(logging.LevelError, "proof verification failed", fields)
+	}
+}
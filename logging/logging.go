@@ -0,0 +1,110 @@
+// Package logging provides the structured logger used by the kit, adapters for
+// logan and slog loggers, and redaction of personal data in log fields.
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+
+	"gitlab.com/distributed_lab/logan/v3"
+)
+
+// Level is a logging level
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// Fields are structured log fields. Field "error" must have error type.
+type Fields map[string]any
+
+// Logger writes the message with fields on the given level. Use FromLogan or
+// FromSlog to adapt your logger.
+type Logger interface {
+	Log(level Level, msg string, fields Fields)
+}
+
+// Nop is a Logger that discards everything
+type Nop struct{}
+
+func (Nop) Log(Level, string, Fields) {}
+
+type loganLogger struct {
+	entry *logan.Entry
+}
+
+// FromLogan adapts logan logger, e.g. from comfig.Logger
+func FromLogan(entry *logan.Entry) Logger {
+	return loganLogger{entry: entry}
+}
+
+func (l loganLogger) Log(level Level, msg string, fields Fields) {
+	entry := l.entry
+	if err, ok := fields["error"].(error); ok {
+		entry = entry.WithError(err)
+		fields = withoutKey(fields, "error")
+	}
+	entry = entry.WithFields(logan.F(fields))
+
+	switch level {
+	case LevelDebug:
+		entry.Debug(msg)
+	case LevelInfo:
+		entry.Info(msg)
+	case LevelWarn:
+		entry.Warn(msg)
+	default:
+		entry.Error(msg)
+	}
+}
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// FromSlog adapts slog logger
+func FromSlog(logger *slog.Logger) Logger {
+	return slogLogger{logger: logger}
+}
+
+var slogLevels = map[Level]slog.Level{
+	LevelDebug: slog.LevelDebug,
+	LevelInfo:  slog.LevelInfo,
+	LevelWarn:  slog.LevelWarn,
+	LevelError: slog.LevelError,
+}
+
+func (l slogLogger) Log(level Level, msg string, fields Fields) {
+	// sorted for deterministic output
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]slog.Attr, len(keys))
+	for i, key := range keys {
+		attrs[i] = slog.Any(key, fields[key])
+	}
+
+	lvl, ok := slogLevels[level]
+	if !ok {
+		lvl = slog.LevelError
+	}
+	l.logger.LogAttrs(context.Background(), lvl, msg, attrs...)
+}
+
+func withoutKey(fields Fields, key string) Fields {
+	res := make(Fields, len(fields))
+	for k, v := range fields {
+		if k != key {
+			res[k] = v
+		}
+	}
+	return res
+}
@@ -0,0 +1,51 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedacted(t *testing.T) {
+	fields := Fields{
+		"nullifier":              "123",
+		"personal_number_hash":   "456",
+		"birth_date_upper_bound": "52983525027888",
+		"proof_type":             "global_passport",
+	}
+
+	var buf bytes.Buffer
+	logger := FromSlog(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	Redacted(logger, HashRedactor(nil)).Log(LevelInfo, "proof rejected", fields)
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "INFO", entry["level"])
+	assert.Equal(t, "proof rejected", entry["msg"])
+	assert.Equal(t, "global_passport", entry["proof_type"])
+	assert.Len(t, entry["nullifier"], 16)
+	assert.NotEqual(t, "123", entry["nullifier"])
+	assert.NotContains(t, entry, "birth_date_upper_bound")
+
+	salted, _ := HashRedactor([]byte("salt"))("nullifier", "123")
+	assert.NotEqual(t, entry["nullifier"], salted)
+
+	// the default one can't be recomputed without the process salt
+	def, _ := DefaultRedactor()("nullifier", "123")
+	same, _ := DefaultRedactor()("nullifier", "123")
+	assert.Equal(t, def, same)
+	assert.NotEqual(t, entry["nullifier"], def)
+
+	buf.Reset()
+	Redacted(logger, DropRedactor()).Log(LevelWarn, "failed", Fields{"nullifier": "123", "error": errors.New("rpc is down")})
+	assert.NotContains(t, buf.String(), "nullifier")
+	assert.Contains(t, buf.String(), "rpc is down")
+}
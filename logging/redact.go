@@ -0,0 +1,90 @@
+package logging
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+)
+
+// Sensitive field names, which are redacted by the built-in redactors. They
+// match the public signal names, e.g. "nullifier".
+var (
+	// HashedFields identify the user, so they are replaced with a hash to
+	// correlate the log entries without revealing the value
+	HashedFields = []string{"nullifier", "personal_number_hash"}
+	// DroppedFields are personal data, which is never logged
+	DroppedFields = []string{"birth_date", "birth_date_lower_bound", "birth_date_upper_bound"}
+)
+
+// Redactor returns the value to log instead of the given one, or false to drop
+// the field
+type Redactor func(key string, value any) (any, bool)
+
+// processSalt is the random salt of DefaultRedactor, unique for each process
+var processSalt = func() []byte {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		panic(fmt.Errorf("failed to generate log salt: %w", err))
+	}
+	return salt
+}()
+
+// DefaultRedactor is HashRedactor with random salt generated on the process
+// start. Nullifiers are public, so the hashes without secret salt can be linked
+// back to the users by anyone who collects them. The entries are correlated
+// within the process only: use HashRedactor with a stable secret salt to
+// correlate them across restarts and replicas.
+func DefaultRedactor() Redactor {
+	return HashRedactor(processSalt)
+}
+
+// HashRedactor replaces HashedFields with truncated salted SHA-256 hash and
+// drops DroppedFields. Use the secret salt to prevent hash lookups by the
+// values, e.g. nullifiers from the blockchain: the empty salt is not safe.
+func HashRedactor(salt []byte) Redactor {
+	return func(key string, value any) (any, bool) {
+		switch {
+		case slices.Contains(DroppedFields, key):
+			return nil, false
+		case slices.Contains(HashedFields, key):
+			h := sha256.New()
+			h.Write(salt)
+			h.Write([]byte(fmt.Sprint(value)))
+			return hex.EncodeToString(h.Sum(nil)[:8]), true
+		default:
+			return value, true
+		}
+	}
+}
+
+// DropRedactor drops both HashedFields and DroppedFields
+func DropRedactor() Redactor {
+	return func(key string, value any) (any, bool) {
+		if slices.Contains(DroppedFields, key) || slices.Contains(HashedFields, key) {
+			return nil, false
+		}
+		return value, true
+	}
+}
+
+type redacted struct {
+	logger   Logger
+	redactor Redactor
+}
+
+// Redacted wraps the logger to apply the redactor to all fields
+func Redacted(l Logger, r Redactor) Logger {
+	return redacted{logger: l, redactor: r}
+}
+
+func (l redacted) Log(level Level, msg string, fields Fields) {
+	res := make(Fields, len(fields))
+	for key, value := range fields {
+		if v, ok := l.redactor(key, value); ok {
+			res[key] = v
+		}
+	}
+	l.logger.Log(level, msg, res)
+}
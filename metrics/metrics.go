@@ -0,0 +1,106 @@
+// Package metrics provides Prometheus implementation of zkverifier_kit.Observer
+// and root.Observer.
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	kit "github.com/rarimo/zkverifier-kit"
+	"github.com/rarimo/zkverifier-kit/root"
+)
+
+// Root call results used in metric labels
+const (
+	rootValid   = "valid"
+	rootInvalid = "invalid"
+	rootError   = "error"
+)
+
+// Metrics collects verification metrics. Register it in your registry:
+//
+//	m := metrics.New("myapp")
+//	prometheus.MustRegister(m)
+//	v, err := kit.NewVerifier(nil, kit.WithObserver(m), ...)
+type Metrics struct {
+	stages        *prometheus.HistogramVec
+	outcomes      *prometheus.CounterVec
+	rootCalls     *prometheus.CounterVec
+	rootDurations *prometheus.HistogramVec
+}
+
+var (
+	_ kit.Observer         = (*Metrics)(nil)
+	_ root.Observer        = (*Metrics)(nil)
+	_ prometheus.Collector = (*Metrics)(nil)
+)
+
+// New creates metrics with optional namespace and "zkverifier" subsystem
+func New(namespace string) *Metrics {
+	const subsystem = "zkverifier"
+
+	return &Metrics{
+		stages: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "stage_duration_seconds",
+			Help:      "Duration of proof verification stages.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"proof_type", "stage"}),
+		outcomes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "verifications_total",
+			Help:      "Proof verification outcomes: valid, internal_error or the code of failed rule.",
+		}, []string{"proof_type", "outcome"}),
+		rootCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "root_calls_total",
+			Help:      "Root verifier calls by result: valid, invalid or error.",
+		}, []string{"verifier", "result"}),
+		rootDurations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "root_call_duration_seconds",
+			Help:      "Duration of root verifier calls.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"verifier"}),
+	}
+}
+
+func (m *Metrics) ObserveStage(proofType string, stage kit.Stage, d time.Duration) {
+	m.stages.WithLabelValues(proofType, string(stage)).Observe(d.Seconds())
+}
+
+func (m *Metrics) ObserveOutcome(proofType string, outcome string) {
+	m.outcomes.WithLabelValues(proofType, outcome).Inc()
+}
+
+func (m *Metrics) ObserveRootCall(typ root.VerifierType, d time.Duration, err error) {
+	result := rootValid
+	switch {
+	case errors.Is(err, root.ErrInvalidRoot):
+		result = rootInvalid
+	case err != nil:
+		result = rootError
+	}
+
+	m.rootCalls.WithLabelValues(string(typ), result).Inc()
+	m.rootDurations.WithLabelValues(string(typ)).Observe(d.Seconds())
+}
+
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.stages.Describe(ch)
+	m.outcomes.Describe(ch)
+	m.rootCalls.Describe(ch)
+	m.rootDurations.Describe(ch)
+}
+
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.stages.Collect(ch)
+	m.outcomes.Collect(ch)
+	m.rootCalls.Collect(ch)
+	m.rootDurations.Collect(ch)
+}
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	kit "github.com/rarimo/zkverifier-kit"
+	"github.com/rarimo/zkverifier-kit/root"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubVerifier struct{ err error }
+
+func (v stubVerifier) VerifyRoot(string) error { return v.err }
+
+func TestMetrics(t *testing.T) {
+	m := New("test")
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(m)
+
+	m.ObserveOutcome("global_passport", kit.OutcomeValid)
+	m.ObserveOutcome("global_passport", string(kit.CodeAgeTooLow))
+	m.ObserveOutcome("global_passport", string(kit.CodeAgeTooLow))
+	m.ObserveStage("global_passport", kit.StageGroth16, time.Millisecond)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.outcomes.WithLabelValues("global_passport", kit.OutcomeValid)))
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.outcomes.WithLabelValues("global_passport", string(kit.CodeAgeTooLow))))
+
+	for _, err := range []error{nil, root.ErrInvalidRoot, errors.New("rpc is down")} {
+		_ = root.NewObservedVerifier(stubVerifier{err: err}, root.PoseidonSMT, m).VerifyRoot("1")
+	}
+
+	for _, result := range []string{rootValid, rootInvalid, rootError} {
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.rootCalls.WithLabelValues(string(root.PoseidonSMT), result)), result)
+	}
+
+	count, err := testutil.GatherAndCount(reg)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, count)
+}
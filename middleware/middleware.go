@@ -0,0 +1,200 @@
+// Package middleware provides net/http middleware, which verifies the proof of
+// each request with kit.Verifier before passing it to the handler.
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	val "github.com/go-ozzo/ozzo-validation/v4"
+	zkptypes "github.com/iden3/go-rapidsnark/types"
+	kit "github.com/rarimo/zkverifier-kit"
+)
+
+// maxBodySize limits the request body read by FromBody
+const maxBodySize = 1 << 20
+
+type ctxKey int
+
+const signalsCtxKey ctxKey = iota
+
+type (
+	// ProofExtractor extracts the proof from the request. Returned error is
+	// rendered as invalid proof with 400 status.
+	ProofExtractor func(r *http.Request) (zkptypes.ZKProof, error)
+	// OptionsProvider returns the options for kit.Verifier.VerifyProof per
+	// request, e.g. kit.WithEventData with the authenticated user. Returned
+	// error is considered internal.
+	OptionsProvider func(r *http.Request) ([]kit.VerifyOption, error)
+	// ErrorWriter writes the response on failure. Validation errors are
+	// validation.Errors, other errors are internal.
+	ErrorWriter func(w http.ResponseWriter, r *http.Request, err error)
+)
+
+// Option configures the middleware
+type Option func(*middleware)
+
+type middleware struct {
+	verifier   *kit.Verifier
+	extract    ProofExtractor
+	options    OptionsProvider
+	writeError ErrorWriter
+}
+
+// WithExtractor sets the way to get the proof from request. Default is
+// FromBody without path.
+func WithExtractor(e ProofExtractor) Option {
+	return func(m *middleware) {
+		m.extract = e
+	}
+}
+
+// WithOptions sets the provider of per-request verification options
+func WithOptions(p OptionsProvider) Option {
+	return func(m *middleware) {
+		m.options = p
+	}
+}
+
+// WithErrorWriter overrides the default JSON:API error response, see WriteJSONAPIError
+func WithErrorWriter(w ErrorWriter) Option {
+	return func(m *middleware) {
+		m.writeError = w
+	}
+}
+
+// New creates the middleware, which verifies the proof and stores its public
+// signals in the request context on success, see Signals. On failure, the
+// request is not passed to the next handler.
+func New(v *kit.Verifier, opts ...Option) func(http.Handler) http.Handler {
+	m := &middleware{
+		verifier:   v,
+		extract:    FromBody(),
+		writeError: WriteJSONAPIError,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proof, err := m.extract(r)
+			if err != nil {
+				m.writeError(w, r, val.Errors{"proof": &kit.Error{Code: kit.CodeProofInvalid, Err: err}})
+				return
+			}
+
+			options := []kit.VerifyOption{kit.WithContext(r.Context())}
+			if m.options != nil {
+				custom, err := m.options(r)
+				if err != nil {
+					m.writeError(w, r, fmt.Errorf("get verify options: %w", err))
+					return
+				}
+				options = append(options, custom...)
+			}
+
+			if err = m.verifier.VerifyProof(proof, options...); err != nil {
+				m.writeError(w, r, err)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), signalsCtxKey, m.verifier.Signals(proof, options...))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Signals returns public signals of the verified proof from the request
+// context, which was passed through the middleware
+func Signals(ctx context.Context) (kit.PubSignalGetter, bool) {
+	signals, ok := ctx.Value(signalsCtxKey).(kit.PubSignalGetter)
+	return signals, ok
+}
+
+// FromBody extracts the proof from JSON body. Provide the path to the proof
+// object if it is nested, e.g. "data", "attributes", "proof". The body is
+// restored, so the next handler can read it again.
+func FromBody(path ...string) ProofExtractor {
+	return func(r *http.Request) (zkptypes.ZKProof, error) {
+		if r.Body == nil {
+			return zkptypes.ZKProof{}, errors.New("empty request body")
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+		if err != nil {
+			return zkptypes.ZKProof{}, fmt.Errorf("read body: %w", err)
+		}
+		if len(body) > maxBodySize {
+			return zkptypes.ZKProof{}, fmt.Errorf("body exceeds %d bytes", maxBodySize)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		raw := json.RawMessage(body)
+		for _, field := range path {
+			var obj map[string]json.RawMessage
+			if err = json.Unmarshal(raw, &obj); err != nil {
+				return zkptypes.ZKProof{}, fmt.Errorf("decode %q parent object: %w", field, err)
+			}
+
+			var ok bool
+			if raw, ok = obj[field]; !ok {
+				return zkptypes.ZKProof{}, fmt.Errorf("field %q is missing", field)
+			}
+		}
+
+		return decodeProof(raw)
+	}
+}
+
+// FromHeader extracts the proof from the header, which contains either JSON or
+// base64-encoded JSON of the proof
+func FromHeader(name string) ProofExtractor {
+	return func(r *http.Request) (zkptypes.ZKProof, error) {
+		value := strings.TrimSpace(r.Header.Get(name))
+		if value == "" {
+			return zkptypes.ZKProof{}, fmt.Errorf("header %s is missing", name)
+		}
+
+		if !strings.HasPrefix(value, "{") {
+			decoded, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return zkptypes.ZKProof{}, fmt.Errorf("decode base64 header %s: %w", name, err)
+			}
+			value = string(decoded)
+		}
+
+		return decodeProof([]byte(value))
+	}
+}
+
+func decodeProof(raw []byte) (zkptypes.ZKProof, error) {
+	var proof zkptypes.ZKProof
+	if err := json.Unmarshal(raw, &proof); err != nil {
+		return proof, fmt.Errorf("decode proof: %w", err)
+	}
+	return proof, nil
+}
+
+// WriteJSONAPIError is the default ErrorWriter. It renders the error with
+// kit.RenderJSONAPI: validation errors get 400 status and internal ones get 500.
+func WriteJSONAPIError(w http.ResponseWriter, _ *http.Request, err error) {
+	errs := kit.RenderJSONAPI(err)
+
+	status := http.StatusBadRequest
+	if errs[0].Status == strconv.Itoa(http.StatusInternalServerError) {
+		status = http.StatusInternalServerError
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{"errors": errs})
+}
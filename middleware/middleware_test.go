@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	kit "github.com/rarimo/zkverifier-kit"
+	"github.com/stretchr/testify/assert"
+)
+
+const proofJSON = `{"proof":{"pi_a":["1"],"pi_b":[["1"]],"pi_c":["1"],"protocol":"groth16"},"pub_signals":["1","2"]}`
+
+func TestMiddleware(t *testing.T) {
+	key, err := os.ReadFile("../example_verification_key.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifier, err := kit.NewVerifier(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("next handler must not be called")
+	})
+
+	testCases := []struct {
+		name   string
+		opts   []Option
+		body   string
+		header string
+		status int
+		code   kit.ErrorCode
+	}{
+		{
+			name:   "Invalid body",
+			body:   "{",
+			status: http.StatusBadRequest,
+			code:   kit.CodeProofInvalid,
+		},
+		{
+			name:   "Missing nested field",
+			opts:   []Option{WithExtractor(FromBody("data", "attributes", "proof"))},
+			body:   `{"data":{"attributes":{}}}`,
+			status: http.StatusBadRequest,
+			code:   kit.CodeProofInvalid,
+		},
+		{
+			name:   "Invalid signals count in nested body",
+			opts:   []Option{WithExtractor(FromBody("data", "attributes", "proof"))},
+			body:   `{"data":{"attributes":{"proof":` + proofJSON + `}}}`,
+			status: http.StatusBadRequest,
+			code:   kit.CodeProofInvalid,
+		},
+		{
+			name:   "Invalid signals count in header",
+			opts:   []Option{WithExtractor(FromHeader("X-Proof"))},
+			header: base64.StdEncoding.EncodeToString([]byte(proofJSON)),
+			status: http.StatusBadRequest,
+			code:   kit.CodeProofInvalid,
+		},
+		{
+			name: "Options failure",
+			opts: []Option{WithOptions(func(r *http.Request) ([]kit.VerifyOption, error) {
+				return nil, errors.New("unauthorized")
+			})},
+			body:   proofJSON,
+			status: http.StatusInternalServerError,
+		},
+		{
+			name: "Invalid options from request",
+			opts: []Option{WithOptions(func(r *http.Request) ([]kit.VerifyOption, error) {
+				return []kit.VerifyOption{kit.WithRarimoAddress("cosmos1abc")}, nil
+			})},
+			body:   proofJSON,
+			status: http.StatusBadRequest,
+			code:   kit.CodeOptionsInvalid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+			r.Header.Set("X-Proof", tc.header)
+			w := httptest.NewRecorder()
+
+			New(verifier, tc.opts...)(next).ServeHTTP(w, r)
+			assert.Equal(t, tc.status, w.Code)
+
+			var resp struct {
+				Errors []kit.JSONAPIError `json:"errors"`
+			}
+			if assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp)) && assert.NotEmpty(t, resp.Errors) {
+				assert.Equal(t, tc.code, resp.Errors[0].Code)
+			}
+		})
+	}
+}
+
+func TestFromBodyRestoresBody(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(proofJSON))
+
+	proof, err := FromBody()(r)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, proof.PubSignals)
+
+	body, err := io.ReadAll(r.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, proofJSON, string(body))
+}
@@ -0,0 +1,81 @@
+package zkverifier_kit
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	val "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Stage is a step of Verifier.VerifyProof reported to Observer
+type Stage string
+
+const (
+	// StageSignals is validation of public signals, it includes StageRoot
+	StageSignals Stage = "signals"
+	// StageRoot is the root verification, usually an RPC call
+	StageRoot Stage = "root"
+	// StageGroth16 is the proof verification with the verification key
+	StageGroth16 Stage = "groth16"
+)
+
+// Outcomes reported to Observer besides the codes of failed rules
+const (
+	OutcomeValid    = "valid"
+	OutcomeInternal = "internal_error"
+)
+
+// Observer receives metrics of Verifier.VerifyProof, see WithObserver. It is
+// called synchronously, so the implementation must be fast and safe for
+// concurrent use. The proof type is passed as proofType.String.
+type Observer interface {
+	// ObserveStage reports the duration of the verification stage
+	ObserveStage(proofType string, stage Stage, d time.Duration)
+	// ObserveOutcome reports the result of verification: OutcomeValid,
+	// OutcomeInternal or, for invalid proof, each distinct ErrorCode of the
+	// failed rules
+	ObserveOutcome(proofType string, outcome string)
+}
+
+type nopObserver struct{}
+
+func (nopObserver) ObserveStage(string, Stage, time.Duration) {}
+
+func (nopObserver) ObserveOutcome(string, string) {}
+
+func (o *VerifyOptions) observeOutcome(err error) {
+	typ := o.proofType.String()
+	if err == nil {
+		o.observer.ObserveOutcome(typ, OutcomeValid)
+		return
+	}
+
+	var errs val.Errors
+	if !errors.As(err, &errs) {
+		o.observer.ObserveOutcome(typ, OutcomeInternal)
+		return
+	}
+
+	for _, code := range errorCodes(errs) {
+		o.observer.ObserveOutcome(typ, code)
+	}
+}
+
+// errorCodes returns sorted distinct codes of the errors
+func errorCodes(errs val.Errors) []string {
+	set := make(map[string]struct{}, len(errs))
+	for _, e := range errs {
+		if code := ErrorCodeOf(e); code != "" {
+			set[string(code)] = struct{}{}
+		}
+	}
+
+	codes := make([]string, 0, len(set))
+	for code := range set {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	return codes
+}
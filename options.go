@@ -1,10 +1,20 @@
 package zkverifier_kit
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	val "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/rarimo/zkverifier-kit/country"
+	"github.com/rarimo/zkverifier-kit/logging"
 	"github.com/rarimo/zkverifier-kit/root"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 // VerifyOptions structure that stores all fields that may be validated before proof verification.
@@ -14,11 +24,20 @@ import (
 type VerifyOptions struct {
 	proofType proofType
 	age       int
+	// maxAge - the age which the person must not reach, -1 when unset
+	maxAge int
 	// citizenships - array of interfaces (for more convenient usage during validation) that stores
 	// all citizenships that accepted in proof. Under the hood, it is a string of Alpha-3 county codes,
 	// described in the ISO 3166 international standard.
-	citizenships  []interface{}
+	citizenships []interface{}
+	// excludedCitizenships - Alpha-3 country codes that must be excluded by
+	// the citizenship mask of the proof
+	excludedCitizenships []string
+	// foldBritish - match British nationalities from the proof as "GBR"
+	foldBritish   bool
 	eventDataRule val.Rule
+	// rarimoAddress - bech32 address which must be in the event data
+	rarimoAddress string
 	// eventID - unique identifier associated with a specific event or interaction within
 	// the protocol execution, may be used to keep track of various steps or actions, this
 	// id is a string with a big integer in decimals format
@@ -35,12 +54,42 @@ type VerifyOptions struct {
 	proofSelectorValue string
 	// documentType - provided document type name without UTF-8 encoding
 	documentType string
+	// dateTolerance - days the exact date signals may differ from the expected ones
+	dateTolerance int
+	// documentValidFor - minimal period the document must remain valid for after verification
+	documentValidFor time.Duration
+	// personalNumbers - allowed PersonalNumberHash values in GeorgianPassport
+	personalNumbers personalNumberSet
+	// excludedPersonalNumbers - denied PersonalNumberHash values in GeorgianPassport
+	excludedPersonalNumbers personalNumberSet
+	// personalNumberLookup - external check of PersonalNumberHash in GeorgianPassport
+	personalNumberLookup PersonalNumberLookup
+	// signalRules - custom rules for public signals, accumulated on each WithSignalRule
+	signalRules map[pubSignalID][]val.Rule
+	// customChecks - arbitrary checks of public signals, accumulated on each WithCustomCheck
+	customChecks []CustomCheck
 	// partEventID - participation event ID in PollParticipation proof type
 	partEventID string
 	// voteVerifier - verifies root in PollParticipation proof type
 	voteVerifier root.Verifier
+	// observer - receives metrics of verification
+	observer Observer
+	// tracer - creates spans of verification
+	tracer trace.Tracer
+	// ctx - parent context of the spans, root verification calls and
+	// personal number lookup
+	ctx context.Context
+	// logger - logs verification decisions with redacted personal data
+	logger logging.Logger
+	// profiles - named sets of options, see WithProfile
+	profiles map[string][]VerifyOption
+	// profile - selected profile name on VerifyProof
+	profile string
 }
 
+// CustomCheck is an arbitrary check of public signals, see WithCustomCheck
+type CustomCheck func(PubSignalGetter) error
+
 // VerifyOption type alias for function that may add new values to VerifyOptions structure.
 // It allows to create convenient methods With... that will add new value to the fields for
 // that structure.
@@ -63,18 +112,66 @@ func WithAgeAbove(age int) VerifyOption {
 	}
 }
 
+// WithAgeBelow adds new age check. It is an integer (e.g. 18, 35, 65) below
+// which the person's age must be in proof. BirthdateLowerBound public signal is
+// used when the birth date is not disclosed.
+func WithAgeBelow(age int) VerifyOption {
+	return func(opts *VerifyOptions) {
+		opts.maxAge = age
+	}
+}
+
+// WithAgeBetween combines WithAgeAbove and WithAgeBelow: the person's age must
+// be at least minAge and less than maxAge. NewVerifier fails if minAge is not
+// less than maxAge.
+func WithAgeBetween(minAge, maxAge int) VerifyOption {
+	return func(opts *VerifyOptions) {
+		opts.age = minAge
+		opts.maxAge = maxAge
+	}
+}
+
 // WithCitizenships adds new available citizenship/s to prove that user is a resident of specified country.
 // Function takes an arbitrary number of strings that consists from Alpha-3 county codes,
-// described in the ISO 3166 international standard (e.g. "USA", "UKR", "TUR").
+// described in the ISO 3166 international standard (e.g. "USA", "UKR", "TUR"), group
+// names (e.g. country.EU), ICAO 9303 British nationalities (e.g.
+// country.BritishNationalOverseas) or special categories (e.g. country.Stateless)
+// from country package. NewVerifier fails on unknown codes.
+//
+// Nationality code from the proof is normalized with country.Normalize before
+// matching, so "D<<" in German passports matches "DEU". British nationalities
+// don't match "GBR" unless WithBritishNationalsAsGBR is set.
 func WithCitizenships(citizenships ...string) VerifyOption {
 	return func(opts *VerifyOptions) {
-		opts.citizenships = make([]interface{}, len(citizenships))
-		for i, ctz := range citizenships {
+		expanded := country.Expand(citizenships...)
+		opts.citizenships = make([]interface{}, len(expanded))
+		for i, ctz := range expanded {
 			opts.citizenships[i] = ctz
 		}
 	}
 }
 
+// WithBritishNationalsAsGBR matches ICAO 9303 British nationalities from the
+// proof, e.g. country.BritishNationalOverseas, as British citizens ("GBR") in
+// WithCitizenships, see country.FoldBritish.
+func WithBritishNationalsAsGBR() VerifyOption {
+	return func(opts *VerifyOptions) {
+		opts.foldBritish = true
+	}
+}
+
+// WithExcludedCitizenships adds a denylist of citizenships. Unlike
+// WithCitizenships, it doesn't require the citizenship disclosure: the
+// CitizenshipMask public signal must have the bits of all the provided countries
+// set, see EncodeCitizenshipMask. Takes Alpha-3 country codes, described in the
+// ISO 3166 international standard (e.g. "RUS", "BLR"), or group names from
+// country package. NewVerifier fails on unknown codes.
+func WithExcludedCitizenships(citizenships ...string) VerifyOption {
+	return func(opts *VerifyOptions) {
+		opts.excludedCitizenships = country.Expand(citizenships...)
+	}
+}
+
 // WithEventData takes raw data for which the proof should be generated. This
 // value format has to be validated before the signals validation because the kit
 // checks ONLY the correspondence of these values. The raw value should be
@@ -85,12 +182,44 @@ func WithCitizenships(citizenships ...string) VerifyOption {
 // side, then converts address to bytes array and passes that bytes to the event
 // data input in proof generation. After this precisely the same value has to be
 // passed in the WithEventData function.
+//
+// For the common formats use EncodeEventData... functions or the corresponding
+// WithEventData... options, so the encoding matches the mobile app.
 func WithEventData(raw []byte) VerifyOption {
 	return func(opts *VerifyOptions) {
 		opts.eventDataRule = eventData(raw)
 	}
 }
 
+// WithEventDataAddress expects Ethereum address in the event data, encoded with
+// EncodeEventDataAddress.
+func WithEventDataAddress(addr common.Address) VerifyOption {
+	return WithEventData(EncodeEventDataAddress(addr))
+}
+
+// WithEventDataUUID expects UUID in the event data, encoded with
+// EncodeEventDataUUID.
+func WithEventDataUUID(id [16]byte) VerifyOption {
+	return WithEventData(EncodeEventDataUUID(id))
+}
+
+// WithEventDataHash expects the event data to commit to the arbitrary payload,
+// see EncodeEventDataHash. Use it when the data doesn't fit 31 bytes.
+func WithEventDataHash(payload []byte) VerifyOption {
+	return WithEventData(EncodeEventDataHash(payload))
+}
+
+// WithRarimoAddress expects Rarimo bech32 address (rarimo1...) in the event
+// data, encoded with EncodeEventDataRarimoAddress. It is the event data check
+// with address validation, so it can't be combined with other WithEventData...
+// options: NewVerifier and VerifyProof fail on invalid address or such
+// combination.
+func WithRarimoAddress(address string) VerifyOption {
+	return func(opts *VerifyOptions) {
+		opts.rarimoAddress = address
+	}
+}
+
 // WithEventID takes event identifier as a string that represents big number in a
 // decimal format, i.e. it is compared with value from proof without conversions.
 // This is used for PollParticipation flow too as verifier's (challenged) event ID.
@@ -151,6 +280,65 @@ func WithDocumentType(docType string) VerifyOption {
 	}
 }
 
+// WithPersonalNumberHashes takes an allowlist of PersonalNumberHash values for
+// GeorgianPassport proof type. Each hash is a decimal string compared with
+// the value from proof without conversions. Empty allowlist, e.g. loaded from
+// empty table, rejects all the proofs.
+func WithPersonalNumberHashes(hashes ...string) VerifyOption {
+	return func(opts *VerifyOptions) {
+		opts.personalNumbers = newPersonalNumberSet(hashes)
+	}
+}
+
+// WithExcludedPersonalNumberHashes takes a denylist of PersonalNumberHash
+// values for GeorgianPassport proof type, in the same format as
+// WithPersonalNumberHashes.
+func WithExcludedPersonalNumberHashes(hashes ...string) VerifyOption {
+	return func(opts *VerifyOptions) {
+		opts.excludedPersonalNumbers = newPersonalNumberSet(hashes)
+	}
+}
+
+// WithPersonalNumberLookup takes an abstract lookup that decides whether the
+// PersonalNumberHash of GeorgianPassport proof is eligible. It is checked along
+// with the allowlist and denylist, if they are provided.
+func WithPersonalNumberLookup(l PersonalNumberLookup) VerifyOption {
+	return func(opts *VerifyOptions) {
+		opts.personalNumberLookup = l
+	}
+}
+
+// WithDocumentValidFor requires the document to remain valid for at least the
+// given duration after verification. Either disclosed ExpirationDate or
+// ExpirationDateLowerBound public signal is checked: the latter must be equal to
+// the date of now+d. By default, the document must not be expired today.
+func WithDocumentValidFor(d time.Duration) VerifyOption {
+	return func(opts *VerifyOptions) {
+		opts.documentValidFor = d
+	}
+}
+
+// WithDateTolerance allows the date public signals, that are checked for exact
+// equality, to differ from the expected date by up to the given days in both
+// directions: BirthdateUpperBound, BirthdateLowerBound and
+// ExpirationDateLowerBound. It also widens the window for CurrentDate of
+// GeorgianPassport, which is one day by default.
+//
+// Clients generate the bounds from the user's local date, while the kit uses
+// UTC, so the proofs generated around midnight in distant time zones fail
+// without tolerance. Each signal is checked independently.
+//
+// The tolerance relaxes the age limits by the same days, because the local date
+// may be either ahead of UTC or behind it: with WithAgeAbove(18) and 1 day the
+// person who turns 18 tomorrow is accepted, with WithAgeBelow(65) the one who
+// turned 65 yesterday is accepted too. Add the tolerance to the age limit on
+// your side, when it must never be exceeded.
+func WithDateTolerance(days int) VerifyOption {
+	return func(opts *VerifyOptions) {
+		opts.dateTolerance = days
+	}
+}
+
 // WithPollParticipationEventID takes decimal eventID
 func WithPollParticipationEventID(id string) VerifyOption {
 	return func(opts *VerifyOptions) {
@@ -166,14 +354,106 @@ func WithPollRootVerifier(v root.Verifier) VerifyOption {
 	}
 }
 
+// WithSignalRule attaches extra validation rule to the public signal. The
+// signal value is passed to the rule as decimal string, and the error is
+// reported under the signal key, e.g. "pub_signals/event_data", unless the
+// built-in check of this signal has already failed. Unlike other options, rules
+// are accumulated: the ones passed to VerifyProof are added to the initial.
+func WithSignalRule(id pubSignalID, rule val.Rule) VerifyOption {
+	return func(opts *VerifyOptions) {
+		// copy to avoid modifying initial options of Verifier on VerifyProof
+		rules := maps.Clone(opts.signalRules)
+		if rules == nil {
+			rules = make(map[pubSignalID][]val.Rule)
+		}
+
+		rules[id] = append(slices.Clip(rules[id]), rule)
+		opts.signalRules = rules
+	}
+}
+
+// WithCustomCheck attaches arbitrary check of public signals. Return
+// validation.Errors with the signal keys, e.g. "pub_signals/event_data", to
+// report failures along with the built-in checks, and
+// validation.NewInternalError to return internal error. Other errors are
+// reported under "pub_signals/custom" key. Checks are accumulated in the same
+// way as in WithSignalRule.
+func WithCustomCheck(check CustomCheck) VerifyOption {
+	return func(opts *VerifyOptions) {
+		opts.customChecks = append(slices.Clip(opts.customChecks), check)
+	}
+}
+
+// WithObserver reports stage latency and outcomes of verification to the
+// observer, e.g. Prometheus metrics from the metrics package. Wrap root
+// verifiers with root.NewObservedVerifier to observe RPC calls.
+func WithObserver(o Observer) VerifyOption {
+	return func(opts *VerifyOptions) {
+		if o == nil {
+			o = nopObserver{}
+		}
+		opts.observer = o
+	}
+}
+
+// WithTracerProvider creates OpenTelemetry spans of VerifyProof and its stages
+// with the provider. Root verifiers of root package create their own spans,
+// see root.PoseidonSMTVerifier.WithTracerProvider.
+func WithTracerProvider(tp trace.TracerProvider) VerifyOption {
+	return func(opts *VerifyOptions) {
+		if tp == nil {
+			tp = noop.NewTracerProvider()
+		}
+		opts.tracer = tp.Tracer(tracerName)
+	}
+}
+
+// WithContext sets the parent context of tracing spans, root verification
+// calls and PersonalNumberLookup. It is intended to be passed in VerifyProof with the request context.
+func WithContext(ctx context.Context) VerifyOption {
+	return func(opts *VerifyOptions) {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		opts.ctx = ctx
+	}
+}
+
+// WithLogger logs the verification decisions, root verifier calls and the
+// resolved options. Nullifiers and personal number hashes are hashed with the
+// random salt of the process and birth dates are dropped with
+// logging.DefaultRedactor, use WithLogRedactor to change it, e.g. to correlate
+// the entries across restarts. Adapt your logger with logging.FromLogan or
+// logging.FromSlog.
+func WithLogger(l logging.Logger) VerifyOption {
+	return WithLogRedactor(l, logging.DefaultRedactor())
+}
+
+// WithLogRedactor is WithLogger with custom redactor, e.g. logging.DropRedactor
+// or logging.HashRedactor with the secret salt
+func WithLogRedactor(l logging.Logger, r logging.Redactor) VerifyOption {
+	return func(opts *VerifyOptions) {
+		if l == nil {
+			opts.logger = logging.Nop{}
+			return
+		}
+		opts.logger = logging.Redacted(l, r)
+	}
+}
+
 // mergeOptions collects all parameters together and fills VerifyOptions struct
 // with it, overwriting existing values
 func mergeOptions(withDefaults bool, opts VerifyOptions, options ...VerifyOption) VerifyOptions {
 	if withDefaults {
 		opts.maxIdentitiesCount = -1
 		opts.age = -1
+		opts.maxAge = -1
 		opts.passportVerifier = root.DisabledVerifier{}
 		opts.proofType = GlobalPassport
+		opts.observer = nopObserver{}
+		opts.tracer = noop.NewTracerProvider().Tracer(tracerName)
+		opts.ctx = context.Background()
+		opts.logger = logging.Nop{}
 	}
 
 	for _, opt := range options {
@@ -182,3 +462,60 @@ func mergeOptions(withDefaults bool, opts VerifyOptions, options ...VerifyOption
 
 	return opts
 }
+
+// validate checks the options consistency, which can't be done in the
+// VerifyOption functions themselves
+func (o *VerifyOptions) validate() error {
+	if o.age != -1 && o.maxAge != -1 && o.age >= o.maxAge {
+		return fmt.Errorf("minimal age %d must be less than maximal age %d", o.age, o.maxAge)
+	}
+
+	if o.rarimoAddress != "" {
+		if o.eventDataRule != nil {
+			return errors.New("rarimo address can't be combined with event data")
+		}
+		if _, err := EncodeEventDataRarimoAddress(o.rarimoAddress); err != nil {
+			return fmt.Errorf("invalid rarimo address: %w", err)
+		}
+	}
+
+	if o.dateTolerance < 0 {
+		return fmt.Errorf("negative date tolerance %d", o.dateTolerance)
+	}
+
+	if o.documentValidFor < 0 {
+		return fmt.Errorf("negative document validity period %s", o.documentValidFor)
+	}
+
+	hasPersonalNumberOpts := o.personalNumbers != nil ||
+		len(o.excludedPersonalNumbers) != 0 ||
+		o.personalNumberLookup != nil
+	if hasPersonalNumberOpts && o.proofType != GeorgianPassport {
+		return errors.New("personal number hash checks are only supported for GeorgianPassport")
+	}
+
+	for _, ctz := range o.citizenships {
+		code := ctz.(string)
+		if _, ok := country.ByAlpha3(code); !ok && !country.IsSpecialCategory(code) && !country.IsBritishNationality(code) {
+			return fmt.Errorf("unknown citizenship %q", ctz)
+		}
+	}
+
+	if _, err := EncodeCitizenshipMask(o.excludedCitizenships...); err != nil {
+		return fmt.Errorf("invalid excluded citizenships: %w", err)
+	}
+
+	return nil
+}
+
+// eventData returns the rule for EventData public signal, which is set either
+// with WithEventData... or WithRarimoAddress
+func (o *VerifyOptions) eventData() val.Rule {
+	if o.rarimoAddress == "" {
+		return o.eventDataRule
+	}
+
+	// the address is checked on options validation
+	data, _ := EncodeEventDataRarimoAddress(o.rarimoAddress)
+	return eventData(data)
+}
@@ -12,10 +12,16 @@ import (
 	zkptypes "github.com/iden3/go-rapidsnark/types"
 	zkpverifier "github.com/iden3/go-rapidsnark/verifier"
 	"github.com/rarimo/zkverifier-kit/root"
+	"github.com/rarimo/zkverifier-kit/zkdate"
 )
 
 var ErrVerificationKeyRequired = errors.New("verification key is required")
 
+// ErrInvalidOptions is returned from NewVerifier and Verifier.VerifyProof when
+// the options are invalid, e.g. conflicting event data options. For
+// VerifyProof it means a bad input of the caller rather than invalid proof.
+var ErrInvalidOptions = errors.New("invalid options")
+
 // Verifier is a structure representing some instance for validation and verification zero knowledge proof
 // generated by Rarimo system.
 type Verifier struct {
@@ -36,6 +42,14 @@ func NewVerifier(verificationKey []byte, options ...VerifyOption) (*Verifier, er
 		opts:            mergeOptions(true, VerifyOptions{}, options...),
 	}
 
+	if err := verifier.opts.validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOptions, err)
+	}
+	if err := verifier.opts.validateProfiles(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOptions, err)
+	}
+	verifier.opts.logConfig()
+
 	file := verifier.opts.verificationKeyFile
 	if file == "" {
 		if len(verificationKey) == 0 {
@@ -57,29 +71,81 @@ func NewVerifier(verificationKey []byte, options ...VerifyOption) (*Verifier, er
 // signals to validate are defined in the VerifyOption list. Firstly, you pass
 // initial values to verify in NewVerifier. In case when custom values are
 // required for different proofs, the options can be passed to VerifyProof, which
-// override the initial ones.
+// override the initial ones. Select the profile registered with WithProfile by
+// UseProfile option, ErrUnknownProfile is returned for unknown name.
 //
 // Filtered validation.Errors are always returned, unless this is internal error.
 // You may use errors.As to assert whether it's validation or internal error.
-func (v *Verifier) VerifyProof(proof zkptypes.ZKProof, options ...VerifyOption) error {
+// Each entry of validation.Errors is *Error with stable ErrorCode, use
+// errors.Is with sentinel errors like ErrAgeTooLow or ErrorCodeOf to check it.
+func (v *Verifier) VerifyProof(proof zkptypes.ZKProof, options ...VerifyOption) (err error) {
+	opts, err := v.resolveOptions(options...)
+	if err != nil {
+		return err
+	}
+
 	v2 := Verifier{
 		verificationKey: v.verificationKey,
-		opts:            mergeOptions(false, v.opts, options...),
+		opts:            opts,
+	}
+
+	if err = v2.opts.validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidOptions, err)
 	}
 
-	if err := v2.validatePubSignals(proof); err != nil {
+	end := v2.opts.startSpan("zkverifier.VerifyProof", v2.opts.spanAttributes()...)
+	defer func() {
+		v2.opts.observeOutcome(err)
+		v2.opts.logDecision(proof, err)
+		end(err)
+	}()
+
+	if err = v2.validatePubSignalsObserved(proof); err != nil {
 		return err
 	}
 
-	if err := zkpverifier.VerifyGroth16(proof, v.verificationKey); err != nil {
+	if err = v2.verifyGroth16(proof); err != nil {
 		return val.Errors{
-			"/proof": fmt.Errorf("groth16 verification failed: %w", err),
+			"/proof": &Error{
+				Code: CodeGroth16Failed,
+				Err:  fmt.Errorf("groth16 verification failed: %w", err),
+			},
 		}
 	}
 
 	return nil
 }
 
+func (v *Verifier) validatePubSignalsObserved(proof zkptypes.ZKProof) (err error) {
+	end := v.opts.startStage(StageSignals)
+	defer func() { end(err) }()
+	return withCodes(v.validatePubSignals(proof), &v.opts)
+}
+
+func (v *Verifier) verifyGroth16(proof zkptypes.ZKProof) (err error) {
+	end := v.opts.startStage(StageGroth16)
+	defer func() { end(err) }()
+	return zkpverifier.VerifyGroth16(proof, v.verificationKey)
+}
+
+func (v *Verifier) verifyRoot(rv root.Verifier, value string) (err error) {
+	start := time.Now()
+	end := v.opts.startStage(StageRoot, rootVerifierAttribute(rv))
+	defer func() {
+		v.opts.logRootCall(rv, time.Since(start), err)
+		end(err)
+	}()
+	return root.VerifyContext(v.opts.ctx, rv, value)
+}
+
+// Signals returns the getter of proof public signals, according to the proof
+// type of the Verifier, which may be overridden by the options.
+func (v *Verifier) Signals(proof zkptypes.ZKProof, options ...VerifyOption) PubSignalGetter {
+	// unknown profile is ignored, because VerifyProof fails with it anyway
+	opts, _ := v.resolveOptions(options...)
+	return PubSignalGetter{ProofType: opts.proofType, Signals: proof.PubSignals}
+}
+
 func (v *Verifier) validatePubSignals(zkProof zkptypes.ZKProof) error {
 	var (
 		signals     = PubSignalGetter{ProofType: v.opts.proofType, Signals: zkProof.PubSignals}
@@ -99,48 +165,66 @@ func (v *Verifier) validatePubSignals(zkProof zkptypes.ZKProof) error {
 		return v.validatePassportSignals(signals)
 	}
 
-	err = v.opts.voteVerifier.VerifyRoot(signals.Get(NullifiersTreeRoot))
+	err = v.verifyRoot(v.opts.voteVerifier, signals.Get(NullifiersTreeRoot))
 	if (err != nil) && (!errors.Is(err, root.ErrInvalidRoot)) {
 		return err // internal error
 	}
 
-	return val.Errors{
+	all := val.Errors{
 		"participation_event_id": validateOnOptSet(signals.Get(ParticipationEventID), v.opts.partEventID, val.In(v.opts.partEventID)),
 		"challenged_event_id":    validateOnOptSet(signals.Get(EventID), v.opts.eventID, val.In(v.opts.eventID)),
 		"nullifiers_tree_root":   err,
-	}.Filter()
+	}
+
+	if err := v.validateCustom(signals, all); err != nil {
+		return err
+	}
+
+	return all.Filter()
 }
 
 func (v *Verifier) validatePassportSignals(signals PubSignalGetter) error {
-	err := v.opts.passportVerifier.VerifyRoot(signals.Get(IdStateRoot))
+	err := v.verifyRoot(v.opts.passportVerifier, signals.Get(IdStateRoot))
 	if (err != nil) && (!errors.Is(err, root.ErrInvalidRoot)) {
 		return err
 	}
 
+	georgian := v.opts.proofType == GeorgianPassport
+	pnErr := val.Validate(signals.Get(PersonalNumberHash), val.When(georgian, val.Required))
+	if pnErr == nil && georgian {
+		pnErr = v.verifyPersonalNumber(signals.Get(PersonalNumberHash))
+		if (pnErr != nil) && (!errors.Is(pnErr, ErrPersonalNumberNotEligible)) {
+			return pnErr
+		}
+	}
+
 	var (
-		now       = time.Now().UTC()
-		today     = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
-		yesterday = today.AddDate(0, 0, -1)
-		tomorrow  = today.AddDate(0, 0, 1)
+		// current date is allowed to differ by one day at least
+		window   = max(v.opts.dateTolerance, 1)
+		today    = truncateDate(time.Now().UTC())
+		earliest = today.AddDate(0, 0, -window)
+		latest   = today.AddDate(0, 0, window)
 	)
 
+	eventDataRule := v.opts.eventData()
 	all := val.Errors{
 		"pub_signals/current_date": val.Validate(signals.Get(CurrentDate), val.When(
-			v.opts.proofType == GeorgianPassport,
+			georgian,
 			val.Required,
-			afterDate(yesterday),
-			beforeDate(tomorrow),
+			afterDate(zkdate.Expiry, earliest),
+			beforeDate(zkdate.Expiry, latest),
 		)),
-		"pub_signals/personal_number_hash": val.Validate(signals.Get(PersonalNumberHash), val.When(
-			v.opts.proofType == GeorgianPassport,
-			val.Required,
-		)),
-		"pub_signals/id_state_root": err,
-		"pub_signals/selector":      validateOnOptSet(signals.Get(Selector), v.opts.proofSelectorValue, val.In(v.opts.proofSelectorValue)),
-		"pub_signals/event_id":      validateOnOptSet(signals.Get(EventID), v.opts.eventID, val.In(v.opts.eventID)),
-		// upper bound is a date: the earlier it is, the higher the age
-		"pub_signals/citizenship":   validateOnOptSet(decodeInt(signals.Get(Citizenship)), v.opts.citizenships, val.In(v.opts.citizenships...)),
-		"pub_signals/event_data":    validateOnOptSet(signals.Get(EventData), v.opts.eventDataRule, v.opts.eventDataRule),
+		"pub_signals/personal_number_hash": pnErr,
+		"pub_signals/id_state_root":        err,
+		"pub_signals/selector":             validateOnOptSet(signals.Get(Selector), v.opts.proofSelectorValue, val.In(v.opts.proofSelectorValue)),
+		"pub_signals/event_id":             validateOnOptSet(signals.Get(EventID), v.opts.eventID, val.In(v.opts.eventID)),
+		"pub_signals/citizenship":          validateOnOptSet(decodeCitizenship(signals.Get(Citizenship), v.opts.foldBritish), v.opts.citizenships, val.In(v.opts.citizenships...)),
+		"pub_signals/citizenship_mask": validateOnOptSet(
+			signals.Get(CitizenshipMask),
+			v.opts.excludedCitizenships,
+			excludedMask(v.opts.excludedCitizenships),
+		),
+		"pub_signals/event_data":    validateOnOptSet(signals.Get(EventData), eventDataRule, eventDataRule),
 		"pub_signals/document_type": validateOnOptSet(decodeInt(signals.Get(DocumentType)), v.opts.documentType, val.In(v.opts.documentType)),
 	}
 
@@ -148,38 +232,120 @@ func (v *Verifier) validatePassportSignals(signals PubSignalGetter) error {
 	maps.Copy(all, v.validatePassportExpiration(signals))
 	maps.Copy(all, v.validateIdentitiesInputs(signals))
 
+	if err := v.validateCustom(signals, all); err != nil {
+		return err
+	}
+
 	return all.Filter()
 }
 
+// validateCustom applies WithSignalRule and WithCustomCheck options, adding
+// errors to the existing ones without overwriting. Returns internal error only.
+func (v *Verifier) validateCustom(signals PubSignalGetter, all val.Errors) error {
+	add := func(key string, err error) {
+		if err != nil && all[key] == nil {
+			all[key] = err
+		}
+	}
+
+	for id, rules := range v.opts.signalRules {
+		err := val.Validate(signals.Get(id), rules...)
+
+		var internal val.InternalError
+		if errors.As(err, &internal) {
+			return internal.InternalError()
+		}
+		add(id.errKey(), err)
+	}
+
+	for _, check := range v.opts.customChecks {
+		err := check(signals)
+
+		var (
+			internal val.InternalError
+			errs     val.Errors
+		)
+		switch {
+		case errors.As(err, &internal):
+			return internal.InternalError()
+		case errors.As(err, &errs):
+			for key, e := range errs {
+				add(key, e)
+			}
+		default:
+			add("pub_signals/custom", err)
+		}
+	}
+
+	return nil
+}
+
 func (v *Verifier) validateBirthDate(signals PubSignalGetter) val.Errors {
-	if v.opts.age == -1 {
+	if v.opts.age == -1 && v.opts.maxAge == -1 {
 		return nil
 	}
 
-	allowedBirthDate := time.Now().UTC().AddDate(-v.opts.age, 0, 0)
-	return ORError(
-		val.Validate(signals.Get(BirthDate), val.Required, beforeDate(allowedBirthDate)),
-		val.Validate(signals.Get(BirthdateUpperBound), val.Required, equalDate(allowedBirthDate)),
-		[2]string{"pub_signals/birth_date", "pub_signals/birth_date_upper_bound"},
+	var (
+		now       = time.Now().UTC()
+		dateRules = []val.Rule{val.Required}
+		bounds    = make(val.Errors, 2)
 	)
+
+	// upper bound is a date: the earlier it is, the higher the age
+	if v.opts.age != -1 {
+		allowedBirthDate := now.AddDate(-v.opts.age, 0, 0)
+		dateRules = append(dateRules, v.opts.codedRule(beforeDate(zkdate.Birth, allowedBirthDate), CodeAgeTooLow))
+		bounds["pub_signals/birth_date_upper_bound"] = val.Validate(
+			signals.Get(BirthdateUpperBound), val.Required, equalDate(zkdate.Birth, allowedBirthDate).withTolerance(v.opts.dateTolerance),
+		)
+	}
+
+	// lower bound is a date: the later it is, the lower the age
+	if v.opts.maxAge != -1 {
+		allowedBirthDate := now.AddDate(-v.opts.maxAge, 0, 0)
+		dateRules = append(dateRules, v.opts.codedRule(afterDate(zkdate.Birth, allowedBirthDate), CodeAgeTooHigh))
+		bounds["pub_signals/birth_date_lower_bound"] = val.Validate(
+			signals.Get(BirthdateLowerBound), val.Required, equalDate(zkdate.Birth, allowedBirthDate).withTolerance(v.opts.dateTolerance),
+		)
+	}
+
+	// OR logic: either disclosed birth date or the bounds should be valid
+	birthDate := signals.Get(BirthDate)
+	birthDateErr := val.Validate(birthDate, dateRules...)
+	if birthDateErr == nil || bounds.Filter() == nil {
+		return nil
+	}
+
+	// the bounds are not used when the birth date is disclosed, so its check is
+	// the one which failed
+	if !zkdate.IsEmpty(birthDate) {
+		return val.Errors{"pub_signals/birth_date": birthDateErr}
+	}
+
+	return bounds
 }
 
 func (v *Verifier) validatePassportExpiration(signals PubSignalGetter) val.Errors {
-	return val.Errors{
-		"pub_signals/expiration_date_lower_bound": val.Validate(
-			signals.Get(ExpirationDateLowerBound),
-			val.When(!isEmptyZKDate(signals.Get(ExpirationDateLowerBound)), equalDate(time.Now().UTC())),
-		),
-		"pub_signals/expiration_date": val.Validate(
-			signals.Get(ExpirationDate),
-			val.When(!isEmptyZKDate(signals.Get(ExpirationDate)), afterDate(time.Now().UTC())),
-		),
+	if v.opts.documentValidFor == 0 {
+		return val.Errors{
+			"pub_signals/expiration_date_lower_bound": val.Validate(
+				signals.Get(ExpirationDateLowerBound),
+				val.When(!zkdate.IsEmpty(signals.Get(ExpirationDateLowerBound)), equalDate(zkdate.Expiry, time.Now().UTC()).withTolerance(v.opts.dateTolerance)),
+			),
+			"pub_signals/expiration_date": val.Validate(
+				signals.Get(ExpirationDate),
+				val.When(!zkdate.IsEmpty(signals.Get(ExpirationDate)), afterDate(zkdate.Expiry, time.Now().UTC())),
+			),
+		}
 	}
-}
 
-// ZKP sets dates to 0 or 52983525027888 if date is not used or is not present in selector
-func isEmptyZKDate(dateStr string) bool {
-	return dateStr == "0" || dateStr == "52983525027888"
+	// empty dates fail on parsing, so one of the signals must be present
+	validUntil := time.Now().UTC().Add(v.opts.documentValidFor)
+	return ORError(
+		val.Validate(signals.Get(ExpirationDate), val.Required, afterDate(zkdate.Expiry, validUntil)),
+		val.Validate(signals.Get(ExpirationDateLowerBound), val.Required, equalDate(zkdate.Expiry, validUntil).withTolerance(v.opts.dateTolerance)),
+		[2]string{"pub_signals/expiration_date", "pub_signals/expiration_date_lower_bound"},
+	)
 }
 
 func (v *Verifier) validateIdentitiesInputs(signals PubSignalGetter) val.Errors {
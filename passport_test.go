@@ -2,14 +2,33 @@ package zkverifier_kit
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"math"
+	"math/big"
+	"net/url"
 	"os"
+	"sort"
 	"testing"
+	"time"
 
+	"github.com/cosmos/btcutil/bech32"
+	"github.com/ethereum/go-ethereum/common"
+	val "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/iden3/go-iden3-crypto/constants"
 	zkptypes "github.com/iden3/go-rapidsnark/types"
+	"github.com/rarimo/zkverifier-kit/country"
+	"github.com/rarimo/zkverifier-kit/logging"
 	"github.com/rarimo/zkverifier-kit/root"
+	"github.com/rarimo/zkverifier-kit/zkdate"
 	"github.com/stretchr/testify/assert"
+	"gitlab.com/distributed_lab/kit/kv"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 // !!!NOTE: Tests will fail if ZKP is not generated at the same date, when these tests are run.
@@ -23,7 +42,7 @@ const (
 
 	ukrCitizenship = "UKR"
 	usaCitizenship = "USA"
-	engCitizenship = "ENG"
+	gbrCitizenship = "GBR"
 
 	validEventID   = "304358862882731539112827930982999386691702727710421481944329166126417129570"
 	invalidEventID = "AC42D1A986804618C7A793FBE814D9B31E47BE51E082806363DCA6958F3062"
@@ -91,6 +110,7 @@ var validProof = zkptypes.ZKProof{
 
 // converted from EventData field in validProof.PubSignals
 var (
+	rarimoAddress    = "rarimo1exzw7q2fytyrurkp5s7tm7ek720we9ejwujf2h"
 	validEventData   = []byte{25, 6, 2, 14, 30, 0, 10, 9, 4, 11, 4, 3, 28, 3, 22, 1, 20, 16, 30, 11, 27, 30, 25, 22, 30, 10, 15, 14, 25, 5, 25, 18}
 	invalidEventData = []byte{174}
 )
@@ -180,7 +200,7 @@ func TestVerifyProof(t *testing.T) {
 			initOpts: []VerifyOption{
 				WithVerificationKeyFile(verificationKeyFile),
 				WithProofSelectorValue("23073"),
-				WithCitizenships(engCitizenship, usaCitizenship),
+				WithCitizenships(gbrCitizenship, usaCitizenship),
 			},
 			want: "pub_signals/citizenship: must be a valid value",
 		},
@@ -373,3 +393,1029 @@ func TestVerifyProof(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateAgeRange(t *testing.T) {
+	var (
+		now      = time.Now().UTC()
+		born20   = zkdate.Encode(now.AddDate(-20, 0, -1))
+		bound18  = zkdate.Encode(now.AddDate(-18, 0, 0))
+		bound25  = zkdate.Encode(now.AddDate(-25, 0, 0))
+		bound30  = zkdate.Encode(now.AddDate(-30, 0, 0))
+		anyBound = zkdate.Encode(now.AddDate(-1, 0, 0))
+	)
+
+	testCases := []struct {
+		name    string
+		opts    []VerifyOption
+		signals map[pubSignalID]string
+		want    string
+	}{
+		{
+			name:    "Disclosed birth date below max age",
+			opts:    []VerifyOption{WithAgeBelow(25)},
+			signals: map[pubSignalID]string{BirthDate: born20},
+		},
+		{
+			name:    "Disclosed birth date above max age",
+			opts:    []VerifyOption{WithAgeBelow(18)},
+			signals: map[pubSignalID]string{BirthDate: born20},
+			want:    "pub_signals/birth_date: date is too early",
+		},
+		{
+			name:    "Disclosed birth date below min age",
+			opts:    []VerifyOption{WithAgeAbove(25)},
+			signals: map[pubSignalID]string{BirthDate: born20},
+			want:    "pub_signals/birth_date: date is too late",
+		},
+		{
+			name:    "Matching lower bound",
+			opts:    []VerifyOption{WithAgeBelow(25)},
+			signals: map[pubSignalID]string{BirthdateLowerBound: bound25},
+		},
+		{
+			name:    "Non-matching lower bound",
+			opts:    []VerifyOption{WithAgeBelow(25)},
+			signals: map[pubSignalID]string{BirthdateLowerBound: anyBound},
+			want:    "pub_signals/birth_date_lower_bound: dates are not equal",
+		},
+		{
+			name:    "Disclosed birth date in range",
+			opts:    []VerifyOption{WithAgeBetween(18, 25)},
+			signals: map[pubSignalID]string{BirthDate: born20},
+		},
+		{
+			name:    "Matching bounds in range",
+			opts:    []VerifyOption{WithAgeBetween(18, 30)},
+			signals: map[pubSignalID]string{BirthdateUpperBound: bound18, BirthdateLowerBound: bound30},
+		},
+		{
+			name:    "Missing lower bound in range",
+			opts:    []VerifyOption{WithAgeBetween(18, 30)},
+			signals: map[pubSignalID]string{BirthdateUpperBound: bound18},
+			want:    "pub_signals/birth_date_lower_bound: invalid date string",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			verifier, err := NewVerifier(verificationKey, tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = verifier.validatePubSignals(newTestProof(GlobalPassport, tc.signals))
+			if tc.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorContains(t, err, tc.want)
+		})
+	}
+
+	_, err := NewVerifier(verificationKey, WithAgeBetween(25, 18))
+	assert.ErrorContains(t, err, "invalid options")
+}
+
+// newTestProof builds a proof with zero public signals, except the provided
+// ones, so it passes the signals validation with no options set
+func newTestProof(typ proofType, signals map[pubSignalID]string) zkptypes.ZKProof {
+	pubSignals := make([]string, PubSignalsCount(typ))
+	for i := range pubSignals {
+		pubSignals[i] = "0"
+	}
+
+	indexes := Indexes(typ)
+	pubSignals[indexes[Nullifier]] = "1"
+	for id, value := range signals {
+		pubSignals[indexes[id]] = value
+	}
+
+	return zkptypes.ZKProof{
+		Proof:      &zkptypes.ProofData{Protocol: "groth16"},
+		PubSignals: pubSignals,
+	}
+}
+
+func TestValidateCitizenships(t *testing.T) {
+	testCases := []struct {
+		name         string
+		citizenships []string
+		opts         []VerifyOption
+		citizenship  string
+		want         string
+	}{
+		{
+			name:         "Matching country",
+			citizenships: []string{usaCitizenship, ukrCitizenship},
+			citizenship:  ukrCitizenship,
+		},
+		{
+			name:         "Matching group",
+			citizenships: []string{country.EU},
+			citizenship:  "DEU",
+		},
+		{
+			name:         "Padded ICAO code",
+			citizenships: []string{"DEU"},
+			citizenship:  "D<<",
+		},
+		{
+			name:         "British nationality is not British citizenship",
+			citizenships: []string{country.EU, gbrCitizenship},
+			citizenship:  "GBN",
+			want:         "pub_signals/citizenship: must be a valid value",
+		},
+		{
+			name:         "British nationality folded to British citizenship",
+			citizenships: []string{country.EU, gbrCitizenship},
+			opts:         []VerifyOption{WithBritishNationalsAsGBR()},
+			citizenship:  "GBN",
+		},
+		{
+			name:         "Matching British nationality",
+			citizenships: []string{country.BritishNationalOverseas},
+			citizenship:  "GBN",
+		},
+		{
+			name:         "Special category",
+			citizenships: []string{ukrCitizenship, country.Stateless},
+			citizenship:  "XXA",
+		},
+		{
+			name:         "Special category is not allowed",
+			citizenships: []string{ukrCitizenship},
+			citizenship:  "UNO",
+			want:         "pub_signals/citizenship: must be a valid value",
+		},
+		{
+			name:         "Non-matching group",
+			citizenships: []string{country.EU, gbrCitizenship},
+			citizenship:  ukrCitizenship,
+			want:         "pub_signals/citizenship: must be a valid value",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := append([]VerifyOption{WithCitizenships(tc.citizenships...)}, tc.opts...)
+			verifier, err := NewVerifier(verificationKey, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = verifier.validatePubSignals(newTestProof(GlobalPassport, map[pubSignalID]string{
+				Citizenship: new(big.Int).SetBytes([]byte(tc.citizenship)).String(),
+			}))
+			if tc.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorContains(t, err, tc.want)
+		})
+	}
+
+	_, err := NewVerifier(verificationKey, WithCitizenships(ukrCitizenship, "ENG"))
+	assert.ErrorContains(t, err, `unknown citizenship "ENG"`)
+}
+
+func TestValidateExcludedCitizenships(t *testing.T) {
+	mask, err := EncodeCitizenshipMask("RUS", "BLR", "PRK")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// regression values of this layout, not taken from a real proof: BLR, PRK
+	// and RUS are 28th, 181st and 189th in the alphabetical list
+	assert.Equal(t, "787702708005066873196190371955258920380031908802639953920", mask.String())
+	assert.Equal(t, "1", must(EncodeCitizenshipMask("ABW")).String())
+	assert.Equal(t, new(big.Int).Lsh(big.NewInt(1), 248).String(), must(EncodeCitizenshipMask("ZWE")).String())
+
+	testCases := []struct {
+		name string
+		mask string
+		want string
+	}{
+		{
+			name: "All countries excluded",
+			mask: mask.String(),
+		},
+		{
+			name: "Extra countries excluded",
+			mask: new(big.Int).SetBit(mask, 0, 1).String(),
+		},
+		{
+			name: "Unrequired country is not excluded",
+			mask: new(big.Int).SetBit(mask, 28, 0).String(),
+		},
+		{
+			name: "Required country is not excluded",
+			mask: must(EncodeCitizenshipMask("PRK")).String(),
+			want: "pub_signals/citizenship_mask: citizenship RUS is not excluded",
+		},
+		{
+			name: "Empty mask",
+			mask: "0",
+			want: "pub_signals/citizenship_mask: citizenship PRK is not excluded",
+		},
+	}
+
+	verifier, err := NewVerifier(verificationKey, WithExcludedCitizenships("RUS", "PRK"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := verifier.validatePubSignals(newTestProof(GlobalPassport, map[pubSignalID]string{
+				CitizenshipMask: tc.mask,
+			}))
+			if tc.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorContains(t, err, tc.want)
+		})
+	}
+
+	_, err = NewVerifier(verificationKey, WithExcludedCitizenships("RUS", "XYZ"))
+	assert.ErrorContains(t, err, `unknown alpha-3 country code "XYZ"`)
+}
+
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func TestValidateDocumentValidFor(t *testing.T) {
+	const validFor = 90 * 24 * time.Hour
+
+	var (
+		now        = time.Now().UTC()
+		lowerBound = zkdate.Encode(now.Add(validFor))
+	)
+
+	testCases := []struct {
+		name    string
+		signals map[pubSignalID]string
+		want    string
+	}{
+		{
+			name:    "Disclosed expiration date far enough",
+			signals: map[pubSignalID]string{ExpirationDate: zkdate.Encode(now.AddDate(1, 0, 0))},
+		},
+		{
+			name:    "Disclosed expiration date too early",
+			signals: map[pubSignalID]string{ExpirationDate: zkdate.Encode(now.AddDate(0, 0, 30))},
+			want:    "pub_signals/expiration_date_lower_bound: invalid date string",
+		},
+		{
+			name:    "Matching lower bound",
+			signals: map[pubSignalID]string{ExpirationDateLowerBound: lowerBound},
+		},
+		{
+			name:    "Lower bound of today",
+			signals: map[pubSignalID]string{ExpirationDateLowerBound: zkdate.Encode(now)},
+			want:    "pub_signals/expiration_date_lower_bound: dates are not equal",
+		},
+		{
+			name:    "Empty dates",
+			signals: map[pubSignalID]string{ExpirationDate: "52983525027888", ExpirationDateLowerBound: "52983525027888"},
+			want:    "pub_signals/expiration_date_lower_bound: invalid date string",
+		},
+	}
+
+	verifier, err := NewVerifier(verificationKey, WithDocumentValidFor(validFor))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := verifier.validatePubSignals(newTestProof(GlobalPassport, tc.signals))
+			if tc.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorContains(t, err, tc.want)
+		})
+	}
+}
+
+func TestValidateDateTolerance(t *testing.T) {
+	var (
+		now     = time.Now().UTC()
+		bound18 = now.AddDate(-18, 0, 0)
+		bound65 = now.AddDate(-65, 0, 0)
+	)
+
+	testCases := []struct {
+		name      string
+		typ       proofType
+		opts      []VerifyOption
+		tolerance int
+		signals   map[pubSignalID]string
+		want      string
+	}{
+		{
+			name:    "Upper bound of the next day without tolerance",
+			signals: map[pubSignalID]string{BirthdateUpperBound: zkdate.Encode(bound18.AddDate(0, 0, 1))},
+			want:    "pub_signals/birth_date_upper_bound: dates are not equal",
+		},
+		{
+			name:      "Upper bound of the next day admits the person turning 18 tomorrow",
+			tolerance: 1,
+			signals:   map[pubSignalID]string{BirthdateUpperBound: zkdate.Encode(bound18.AddDate(0, 0, 1))},
+		},
+		{
+			name:      "Upper bound of the previous day",
+			tolerance: 1,
+			signals:   map[pubSignalID]string{BirthdateUpperBound: zkdate.Encode(bound18.AddDate(0, 0, -1))},
+		},
+		{
+			name:      "Lower bound of the previous day admits the person turned 65 yesterday",
+			opts:      []VerifyOption{WithAgeBelow(65)},
+			tolerance: 1,
+			signals: map[pubSignalID]string{
+				BirthdateUpperBound: zkdate.Encode(bound18),
+				BirthdateLowerBound: zkdate.Encode(bound65.AddDate(0, 0, -1)),
+			},
+		},
+		{
+			name:      "Lower bound out of tolerance",
+			opts:      []VerifyOption{WithAgeBelow(65)},
+			tolerance: 1,
+			signals: map[pubSignalID]string{
+				BirthdateUpperBound: zkdate.Encode(bound18),
+				BirthdateLowerBound: zkdate.Encode(bound65.AddDate(0, 0, -2)),
+			},
+			want: "pub_signals/birth_date_lower_bound: dates are not equal",
+		},
+		{
+			name:      "Upper bound out of tolerance",
+			tolerance: 1,
+			signals:   map[pubSignalID]string{BirthdateUpperBound: zkdate.Encode(bound18.AddDate(0, 0, 2))},
+			want:      "pub_signals/birth_date_upper_bound: dates are not equal",
+		},
+		{
+			name:      "Georgian current date in tolerance",
+			typ:       GeorgianPassport,
+			tolerance: 2,
+			signals: map[pubSignalID]string{
+				BirthdateUpperBound: zkdate.Encode(bound18),
+				PersonalNumberHash:  "1",
+				CurrentDate:         zkdate.Encode(now.AddDate(0, 0, -2)),
+			},
+		},
+		{
+			name: "Georgian current date out of tolerance",
+			typ:  GeorgianPassport,
+			signals: map[pubSignalID]string{
+				BirthdateUpperBound: zkdate.Encode(bound18),
+				PersonalNumberHash:  "1",
+				CurrentDate:         zkdate.Encode(now.AddDate(0, 0, -2)),
+			},
+			want: "pub_signals/current_date: date is too early",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := append([]VerifyOption{
+				WithProofType(tc.typ),
+				WithAgeAbove(18),
+				WithDateTolerance(tc.tolerance),
+			}, tc.opts...)
+			verifier, err := NewVerifier(verificationKey, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = verifier.validatePubSignals(newTestProof(tc.typ, tc.signals))
+			if tc.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorContains(t, err, tc.want)
+		})
+	}
+}
+
+type personalNumberLookup map[string]bool
+
+func (l personalNumberLookup) IsEligible(ctx context.Context, hash string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	eligible, ok := l[hash]
+	if !ok {
+		return false, errors.New("unknown hash")
+	}
+	return eligible, nil
+}
+
+func TestValidatePersonalNumber(t *testing.T) {
+	lookup := personalNumberLookup{"1": true, "2": false}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testCases := []struct {
+		name string
+		opts []VerifyOption
+		hash string
+		want string
+	}{
+		{
+			name: "Allowed hash",
+			opts: []VerifyOption{WithPersonalNumberHashes("1", "2")},
+			hash: "2",
+		},
+		{
+			name: "Hash is not in allowlist",
+			opts: []VerifyOption{WithPersonalNumberHashes("1", "2")},
+			hash: "3",
+			want: "pub_signals/personal_number_hash: " + ErrPersonalNumberNotEligible.Error(),
+		},
+		{
+			name: "Empty allowlist",
+			opts: []VerifyOption{WithPersonalNumberHashes()},
+			hash: "1",
+			want: "pub_signals/personal_number_hash: " + ErrPersonalNumberNotEligible.Error(),
+		},
+		{
+			name: "Excluded hash",
+			opts: []VerifyOption{WithPersonalNumberHashes("1", "2"), WithExcludedPersonalNumberHashes("2")},
+			hash: "2",
+			want: "pub_signals/personal_number_hash: " + ErrPersonalNumberNotEligible.Error(),
+		},
+		{
+			name: "Eligible by lookup",
+			opts: []VerifyOption{WithPersonalNumberLookup(lookup)},
+			hash: "1",
+		},
+		{
+			name: "Not eligible by lookup",
+			opts: []VerifyOption{WithPersonalNumberLookup(lookup)},
+			hash: "2",
+			want: "pub_signals/personal_number_hash: " + ErrPersonalNumberNotEligible.Error(),
+		},
+		{
+			name: "Lookup failure",
+			opts: []VerifyOption{WithPersonalNumberLookup(lookup)},
+			hash: "3",
+			want: "lookup personal number hash: unknown hash",
+		},
+		{
+			name: "Lookup with canceled context",
+			opts: []VerifyOption{WithPersonalNumberLookup(lookup), WithContext(canceled)},
+			hash: "1",
+			want: "lookup personal number hash: " + context.Canceled.Error(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := append([]VerifyOption{WithProofType(GeorgianPassport)}, tc.opts...)
+			verifier, err := NewVerifier(verificationKey, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = verifier.validatePubSignals(newTestProof(GeorgianPassport, map[pubSignalID]string{
+				PersonalNumberHash: tc.hash,
+				CurrentDate:        zkdate.Encode(time.Now().UTC()),
+			}))
+			if tc.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorContains(t, err, tc.want)
+		})
+	}
+
+	_, err := NewVerifier(verificationKey, WithPersonalNumberHashes("1"))
+	assert.ErrorContains(t, err, "only supported for GeorgianPassport")
+}
+
+func TestDeriveEventID(t *testing.T) {
+	id := DeriveEventID("rewards", "airdrop")
+	assert.Equal(t, id, DeriveEventID("rewards", "airdrop"))
+	assert.NotEqual(t, id, DeriveEventID("rewards-staging", "airdrop"))
+	assert.NotEqual(t, id, DeriveEventID("reward", "sairdrop"))
+
+	parsed, ok := new(big.Int).SetString(id, 10)
+	assert.True(t, ok)
+	assert.Equal(t, -1, parsed.Cmp(constants.Q))
+
+	// regression values of this construction, computed with an independent
+	// keccak256 implementation, they are not reference vectors of Rarimo apps
+	assert.Equal(t,
+		"9636413320534286481214289434978987601215616786379892426449820129367735891608",
+		DeriveEventID("rewards-svc", "airdrop-2024"))
+	assert.Equal(t,
+		"7594343479685364011485532356049589986683437425126738358223829272590769616271",
+		DeriveEventIDFromPayload("", nil))
+	assert.Equal(t,
+		"13587534694812348497080326957183218446017516907363123776450048004738177597636",
+		DeriveEventIDFromPayload("voting", common.LeftPadBytes([]byte{0xff}, 32)))
+}
+
+func TestValidateEventData(t *testing.T) {
+	var (
+		addr    = common.HexToAddress("0x00a329c0648769a73afac7f9381e08fb43dbea72")
+		id      = [16]byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00}
+		payload = []byte(`{"campaign":"airdrop","user":42}`)
+	)
+
+	encode := func(b []byte) string {
+		return new(big.Int).SetBytes(b).String()
+	}
+
+	testCases := []struct {
+		name   string
+		opt    VerifyOption
+		signal string
+		want   string
+	}{
+		{
+			name:   "Matching address with leading zero",
+			opt:    WithEventDataAddress(addr),
+			signal: encode(addr.Bytes()),
+		},
+		{
+			name:   "Non-matching address",
+			opt:    WithEventDataAddress(common.HexToAddress("0x1")),
+			signal: encode(addr.Bytes()),
+			want:   "pub_signals/event_data: event data does not match",
+		},
+		{
+			name:   "Matching UUID",
+			opt:    WithEventDataUUID(id),
+			signal: encode(id[:]),
+		},
+		{
+			name:   "Matching Rarimo address",
+			opt:    WithRarimoAddress(rarimoAddress),
+			signal: validProof.PubSignals[10],
+		},
+		{
+			name:   "Non-matching Rarimo address",
+			opt:    WithRarimoAddress(rarimoAddress),
+			signal: encode(addr.Bytes()),
+			want:   "pub_signals/event_data: event data does not match",
+		},
+		{
+			name:   "Matching payload hash",
+			opt:    WithEventDataHash(payload),
+			signal: encode(EncodeEventDataHash(payload)),
+		},
+		{
+			name:   "Non-matching payload hash",
+			opt:    WithEventDataHash(payload),
+			signal: encode(EncodeEventDataHash([]byte("{}"))),
+			want:   "pub_signals/event_data: event data does not match",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			verifier, err := NewVerifier(verificationKey, tc.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = verifier.validatePubSignals(newTestProof(GlobalPassport, map[pubSignalID]string{
+				EventData: tc.signal,
+			}))
+			if tc.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorContains(t, err, tc.want)
+		})
+	}
+
+	_, err := NewVerifier(verificationKey, WithRarimoAddress(must(bech32.Encode("cosmos", validEventData))))
+	assert.ErrorContains(t, err, "invalid address prefix")
+
+	_, err = NewVerifier(verificationKey, WithRarimoAddress(rarimoAddress), WithEventData(validEventData))
+	assert.ErrorContains(t, err, "rarimo address can't be combined with event data")
+
+	_, err = EncodeEventDataString("a string which is longer than 31 bytes")
+	assert.Error(t, err)
+	assert.Len(t, EncodeEventDataHash(payload), 31)
+}
+
+func TestValidateCustomRules(t *testing.T) {
+	signals := map[pubSignalID]string{
+		EventData:           "42",
+		TimestampUpperBound: "1713436478",
+	}
+
+	testCases := []struct {
+		name string
+		opts []VerifyOption
+		want string
+	}{
+		{
+			name: "Valid signal rule",
+			opts: []VerifyOption{WithSignalRule(EventData, val.In("42"))},
+		},
+		{
+			name: "Invalid signal rule",
+			opts: []VerifyOption{WithSignalRule(EventData, val.In("42")), WithSignalRule(EventData, val.Length(3, 3))},
+			want: "pub_signals/event_data: the length must be exactly 3",
+		},
+		{
+			name: "Failing check with key",
+			opts: []VerifyOption{WithCustomCheck(func(s PubSignalGetter) error {
+				return val.Errors{TimestampUpperBound.errKey(): errors.New("too late")}
+			})},
+			want: "pub_signals/timestamp_upper_bound: too late",
+		},
+		{
+			name: "Failing check without key",
+			opts: []VerifyOption{WithCustomCheck(func(s PubSignalGetter) error {
+				return errors.New("forbidden")
+			})},
+			want: "pub_signals/custom: forbidden",
+		},
+		{
+			name: "Internal error of check",
+			opts: []VerifyOption{WithCustomCheck(func(s PubSignalGetter) error {
+				return val.NewInternalError(errors.New("db is down"))
+			})},
+			want: "db is down",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			verifier, err := NewVerifier(verificationKey, tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = verifier.validatePubSignals(newTestProof(GlobalPassport, signals))
+			if tc.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorContains(t, err, tc.want)
+		})
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	verifier, err := NewVerifier(verificationKey, WithAgeAbove(18), WithCitizenships(ukrCitizenship))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = verifier.VerifyProof(newTestProof(GlobalPassport, map[pubSignalID]string{
+		Citizenship:         new(big.Int).SetBytes([]byte(usaCitizenship)).String(),
+		BirthdateUpperBound: zkdate.Encode(time.Now().UTC()),
+	}))
+
+	var errs val.Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected validation errors, got %v", err)
+	}
+
+	assert.ErrorIs(t, errs["pub_signals/birth_date_upper_bound"], ErrAgeTooLow)
+	assert.ErrorIs(t, errs["pub_signals/citizenship"], ErrCitizenshipNotAllowed)
+	assert.NotErrorIs(t, errs["pub_signals/citizenship"], ErrAgeTooLow)
+	assert.Equal(t, CodeAgeTooLow, ErrorCodeOf(errs["pub_signals/birth_date_upper_bound"]))
+
+	rendered := RenderJSONAPI(err)
+	if assert.Len(t, rendered, 2) {
+		assert.Equal(t, JSONAPIError{
+			Status: "400",
+			Code:   CodeAgeTooLow,
+			Title:  CodeAgeTooLow.Title(),
+			Detail: "dates are not equal",
+			Source: &JSONAPIErrSource{Pointer: "/pub_signals/birth_date_upper_bound"},
+		}, rendered[0])
+		assert.Equal(t, CodeCitizenshipNotAllowed, rendered[1].Code)
+	}
+
+	// disclosed birth date is coded by the failed age limit
+	err = verifier.VerifyProof(newTestProof(GlobalPassport, map[pubSignalID]string{
+		Citizenship: new(big.Int).SetBytes([]byte(ukrCitizenship)).String(),
+		BirthDate:   zkdate.Encode(time.Now().UTC().AddDate(-10, 0, 0)),
+	}))
+	var coded *Error
+	if assert.ErrorAs(t, err, &errs) && assert.ErrorAs(t, errs["pub_signals/birth_date"], &coded) {
+		assert.Equal(t, CodeAgeTooLow, coded.Code)
+		assert.Equal(t, map[string]any{ParamAge: 18}, coded.Params)
+	}
+
+	problem := RenderProblem(errors.New("internal"))
+	assert.Equal(t, 500, problem.Status)
+	assert.Empty(t, problem.Errors)
+}
+
+type recordingObserver struct {
+	stages    []Stage
+	outcomes  []string
+	rootCalls []root.VerifierType
+}
+
+func (o *recordingObserver) ObserveRootCall(typ root.VerifierType, _ time.Duration, _ error) {
+	o.rootCalls = append(o.rootCalls, typ)
+}
+
+func (o *recordingObserver) ObserveStage(proofType string, stage Stage, _ time.Duration) {
+	o.stages = append(o.stages, stage)
+}
+
+func (o *recordingObserver) ObserveOutcome(proofType string, outcome string) {
+	o.outcomes = append(o.outcomes, proofType+"/"+outcome)
+}
+
+func TestObserver(t *testing.T) {
+	observer := new(recordingObserver)
+	verifier, err := NewVerifier(verificationKey,
+		WithAgeAbove(18),
+		WithCitizenships(ukrCitizenship),
+		WithObserver(observer),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = verifier.VerifyProof(newTestProof(GlobalPassport, map[pubSignalID]string{
+		Citizenship:         new(big.Int).SetBytes([]byte(usaCitizenship)).String(),
+		BirthdateUpperBound: zkdate.Encode(time.Now().UTC()),
+	}))
+	assert.Error(t, err)
+
+	assert.Equal(t, []Stage{StageRoot, StageSignals}, observer.stages)
+	assert.Equal(t, []string{
+		"global_passport/" + string(CodeAgeTooLow),
+		"global_passport/" + string(CodeCitizenshipNotAllowed),
+	}, observer.outcomes)
+}
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	verifier, err := NewVerifier(verificationKey, WithAgeAbove(18), WithEventID(validEventID), WithTracerProvider(tp))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	birthDate := zkdate.Encode(time.Now().UTC())
+	err = verifier.VerifyProof(newTestProof(GlobalPassport, map[pubSignalID]string{
+		BirthdateUpperBound: birthDate,
+	}))
+	assert.Error(t, err)
+
+	spans := recorder.Ended()
+	names := make([]string, len(spans))
+	for i, span := range spans {
+		names[i] = span.Name()
+		for _, attr := range span.Attributes() {
+			assert.NotContains(t, attr.Value.Emit(), birthDate, "signal value is recorded in %s", attr.Key)
+		}
+	}
+	assert.Equal(t, []string{"zkverifier.root", "zkverifier.signals", "zkverifier.VerifyProof"}, names)
+
+	attrs := attribute.NewSet(spans[2].Attributes()...)
+	rules, _ := attrs.Value(AttrFailedRules)
+	assert.Contains(t, rules.AsStringSlice(), "pub_signals/birth_date_upper_bound")
+	event, _ := attrs.Value(AttrEventID)
+	assert.Equal(t, validEventID, event.AsString())
+	assert.Equal(t, codes.Error, spans[2].Status().Code)
+}
+
+type recordingLogger []logging.Fields
+
+func (l *recordingLogger) Log(_ logging.Level, msg string, fields logging.Fields) {
+	fields["msg"] = msg
+	*l = append(*l, fields)
+}
+
+func TestLogger(t *testing.T) {
+	logger := new(recordingLogger)
+	verifier, err := NewVerifier(verificationKey, WithAgeAbove(18), WithLogger(logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nullifier := "7639957125598480790492529006924434106731566948760118579546114507674255247458"
+	err = verifier.VerifyProof(newTestProof(GlobalPassport, map[pubSignalID]string{
+		Nullifier:           nullifier,
+		BirthdateUpperBound: zkdate.Encode(time.Now().UTC()),
+	}))
+	assert.Error(t, err)
+
+	msgs := make([]any, len(*logger))
+	for i, fields := range *logger {
+		msgs[i] = fields["msg"]
+	}
+	assert.Equal(t, []any{"verifier configured", "root verified", "proof rejected"}, msgs)
+
+	rejected := (*logger)[2]
+	assert.Equal(t, []string{string(CodeAgeTooLow)}, rejected["error_codes"])
+	assert.NotEqual(t, nullifier, rejected["nullifier"])
+	assert.NotEmpty(t, rejected["nullifier"])
+	unsalted, _ := logging.HashRedactor(nil)("nullifier", nullifier)
+	assert.NotEqual(t, unsalted, rejected["nullifier"])
+	assert.NotContains(t, fmt.Sprint(rejected), zkdate.Encode(time.Now().UTC()))
+}
+
+type failingRootVerifier struct{ err error }
+
+func (v failingRootVerifier) VerifyRoot(string) error { return v.err }
+
+func TestLoggerRedactsErrors(t *testing.T) {
+	logger := new(recordingLogger)
+	rpcErr := &url.Error{Op: "Post", URL: "https://rpc.example.com/v1/secret-key", Err: errors.New("connection refused")}
+	verifier, err := NewVerifier(verificationKey,
+		WithAgeAbove(18),
+		WithLogger(logger),
+		WithPassportRootVerifier(failingRootVerifier{err: rpcErr}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.ErrorIs(t, verifier.VerifyProof(newTestProof(GlobalPassport, nil)), rpcErr)
+	call := (*logger)[1]
+	assert.Equal(t, "root verification call failed", call["msg"])
+	assert.Equal(t, "network", call["error_kind"])
+	assert.EqualError(t, (*logger)[2]["error"].(error), `Post "[redacted]": connection refused`)
+	assert.NotContains(t, fmt.Sprint(*logger), "secret-key")
+
+	// 991399 has invalid month, the raw date bytes must not get into reasons
+	malformed := new(big.Int).SetBytes([]byte("991399")).String()
+	verifier, err = NewVerifier(verificationKey, WithAgeAbove(18), WithLogger(logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Error(t, verifier.VerifyProof(newTestProof(GlobalPassport, map[pubSignalID]string{
+		BirthdateUpperBound: malformed,
+	})))
+	rejected := (*logger)[len(*logger)-1]
+	assert.Equal(t, "proof rejected", rejected["msg"])
+	assert.NotEmpty(t, rejected["reasons"])
+	assert.NotContains(t, fmt.Sprint(rejected), "991399")
+	assert.NotContains(t, fmt.Sprint(rejected), malformed)
+}
+
+func TestNewVerifierFromConfig(t *testing.T) {
+	policy := func(fields map[string]interface{}) kv.Getter {
+		block := map[string]interface{}{"verification_key": verificationKeyFile}
+		maps.Copy(block, fields)
+
+		return kv.GetterFunc(func(key string) (map[string]interface{}, error) {
+			switch key {
+			case "policy":
+				return block, nil
+			case string(root.PoseidonSMT):
+				return map[string]interface{}{"disabled": true}, nil
+			case "invalid_root":
+				return map[string]interface{}{"rpc": "http://127.0.0.1:1", "contract": "0x01"}, nil
+			default:
+				return nil, nil
+			}
+		})
+	}
+
+	v, err := NewVerifierFromConfig(policy(map[string]interface{}{
+		"proof_type":            "georgian_passport",
+		"root_verifier":         string(root.PoseidonSMT),
+		"age_above":             18,
+		"age_below":             65,
+		"citizenships":          []interface{}{"GEO", "UKR"},
+		"excluded_citizenships": []string{"RUS"},
+		"event_id":              validEventID,
+		"identities_counter":    1,
+		"document_valid_for":    "2160h",
+		"date_tolerance":        1,
+	}), "policy")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, GeorgianPassport, v.opts.proofType)
+	assert.Equal(t, root.DisabledVerifier{}, v.opts.passportVerifier)
+	assert.Equal(t, 18, v.opts.age)
+	assert.Equal(t, 65, v.opts.maxAge)
+	assert.Equal(t, []interface{}{"GEO", "UKR"}, v.opts.citizenships)
+	assert.Equal(t, []string{"RUS"}, v.opts.excludedCitizenships)
+	assert.Equal(t, validEventID, v.opts.eventID)
+	assert.Equal(t, int64(1), v.opts.maxIdentitiesCount)
+	assert.Equal(t, 90*24*time.Hour, v.opts.documentValidFor)
+	assert.Equal(t, 1, v.opts.dateTolerance)
+
+	for name, fields := range map[string]map[string]interface{}{
+		"unknown proof type":    {"proof_type": "driver_license"},
+		"unknown root verifier": {"root_verifier": "custom"},
+		"invalid root config":   {"root_verifier": string(root.PoseidonSMT), "root_verifier_config": "invalid_root"},
+		"missing root config":   {"root_verifier": string(root.PoseidonSMT), "root_verifier_config": "missing_root"},
+		"unknown citizenship":   {"citizenships": []string{"ENG"}},
+		"missing key":           {"verification_key": ""},
+	} {
+		_, err = NewVerifierFromConfig(policy(fields), "policy")
+		assert.Error(t, err, name)
+	}
+}
+
+func TestNewVerifierFromConfigObservesRoot(t *testing.T) {
+	getter := kv.GetterFunc(func(key string) (map[string]interface{}, error) {
+		switch key {
+		case "policy":
+			return map[string]interface{}{
+				"verification_key":     verificationKeyFile,
+				"root_verifier":        string(root.PoseidonSMT),
+				"root_verifier_config": "mainnet_root",
+			}, nil
+		case "mainnet_root":
+			return map[string]interface{}{
+				"rpc":             "http://127.0.0.1:1",
+				"contract":        "0x0000000000000000000000000000000000000001",
+				"request_timeout": "1s",
+			}, nil
+		default:
+			return nil, nil
+		}
+	})
+
+	observer := new(recordingObserver)
+	v, err := NewVerifierFromConfig(getter, "policy", WithObserver(observer))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Error(t, v.opts.passportVerifier.VerifyRoot("1"))
+	assert.Equal(t, []root.VerifierType{root.PoseidonSMT}, observer.rootCalls)
+}
+
+func TestProfiles(t *testing.T) {
+	verifier, err := NewVerifier(verificationKey,
+		WithAgeAbove(equalAge),
+		WithProfile("adult_ukr", WithCitizenships(ukrCitizenship)),
+		WithProfile("any_adult"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"adult_ukr", "any_adult"}, verifier.Profiles())
+
+	proof := newTestProof(GlobalPassport, map[pubSignalID]string{
+		Citizenship:         new(big.Int).SetBytes([]byte(usaCitizenship)).String(),
+		BirthdateUpperBound: zkdate.Encode(time.Now().UTC().AddDate(-equalAge, 0, 0)),
+	})
+
+	errKeys := func(err error) []string {
+		var errs val.Errors
+		if !errors.As(err, &errs) {
+			t.Fatalf("expected validation errors, got %v", err)
+		}
+		keys := make([]string, 0, len(errs))
+		for key := range errs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return keys
+	}
+
+	// groth16 fails on the fake proof, when all the signals are valid
+	assert.Equal(t, []string{"/proof"}, errKeys(verifier.VerifyProof(proof, UseProfile("any_adult"))))
+	assert.Equal(t, []string{"pub_signals/citizenship"}, errKeys(verifier.VerifyProof(proof, UseProfile("adult_ukr"))))
+	// local options override the profile regardless of the position
+	assert.Equal(t, []string{"/proof"}, errKeys(verifier.VerifyProof(proof,
+		WithCitizenships(usaCitizenship),
+		UseProfile("adult_ukr"),
+	)))
+
+	err = verifier.VerifyProof(proof, UseProfile("poll_123"))
+	assert.ErrorIs(t, err, ErrUnknownProfile)
+	assert.EqualError(t, err, `unknown profile "poll_123"`)
+
+	_, err = NewVerifier(verificationKey, WithProfile("invalid", WithCitizenships("ENG")))
+	assert.ErrorContains(t, err, `profile "invalid"`)
+
+	// the key is shared, so another circuit needs another Verifier
+	_, err = NewVerifier(verificationKey, WithProfile("poll", WithProofType(PollParticipation)))
+	assert.ErrorContains(t, err, `profile "poll": proof type`)
+	_, err = NewVerifier(verificationKey, WithProfile("key", WithVerificationKeyFile(verificationKeyFile)))
+	assert.ErrorContains(t, err, `profile "key": verification key`)
+	_, err = NewVerifier(nil, WithVerificationKeyFile(verificationKeyFile),
+		WithProfile("same", WithProofType(GlobalPassport), WithVerificationKeyFile(verificationKeyFile)))
+	assert.NoError(t, err)
+}
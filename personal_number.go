@@ -0,0 +1,57 @@
+package zkverifier_kit
+
+import (
+	"context"
+	"fmt"
+)
+
+// PersonalNumberLookup is an abstraction to check the eligibility of the person
+// by PersonalNumberHash public signal against some external storage, e.g.
+// database of the pilot programme participants. Hash is provided as decimal
+// string from the proof, context is the one of WithContext. Returned error is
+// considered internal.
+type PersonalNumberLookup interface {
+	IsEligible(ctx context.Context, hash string) (bool, error)
+}
+
+type personalNumberSet map[string]struct{}
+
+func newPersonalNumberSet(hashes []string) personalNumberSet {
+	set := make(personalNumberSet, len(hashes))
+	for _, h := range hashes {
+		set[h] = struct{}{}
+	}
+	return set
+}
+
+func (s personalNumberSet) has(hash string) bool {
+	_, ok := s[hash]
+	return ok
+}
+
+// verifyPersonalNumber returns ErrPersonalNumberNotEligible when any of the
+// personal number options rejects the hash, or internal error of the lookup.
+// The allowlist is set when it is non-nil, so the empty one rejects any hash.
+func (v *Verifier) verifyPersonalNumber(hash string) error {
+	if v.opts.personalNumbers != nil && !v.opts.personalNumbers.has(hash) {
+		return ErrPersonalNumberNotEligible
+	}
+
+	if v.opts.excludedPersonalNumbers.has(hash) {
+		return ErrPersonalNumberNotEligible
+	}
+
+	if v.opts.personalNumberLookup == nil {
+		return nil
+	}
+
+	ok, err := v.opts.personalNumberLookup.IsEligible(v.opts.ctx, hash)
+	if err != nil {
+		return fmt.Errorf("lookup personal number hash: %w", err)
+	}
+	if !ok {
+		return ErrPersonalNumberNotEligible
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package zkverifier_kit
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+)
+
+// ErrUnknownProfile is returned from Verifier.VerifyProof when UseProfile
+// refers to the profile missing in the Verifier
+var ErrUnknownProfile = errors.New("unknown profile")
+
+// WithProfile registers named set of options in Verifier, which is selected
+// with UseProfile on Verifier.VerifyProof, e.g. a campaign policy. It is
+// intended for NewVerifier only. The profile is validated together with the
+// other NewVerifier options. All the profiles share the verification key, so
+// WithVerificationKeyFile and WithProofType are not allowed in a profile: create
+// a separate Verifier for another circuit.
+func WithProfile(name string, options ...VerifyOption) VerifyOption {
+	return func(opts *VerifyOptions) {
+		profiles := maps.Clone(opts.profiles)
+		if profiles == nil {
+			profiles = make(map[string][]VerifyOption)
+		}
+
+		profiles[name] = options
+		opts.profiles = profiles
+	}
+}
+
+// UseProfile selects the profile registered with WithProfile. The options are
+// always applied in the order: NewVerifier options, profile options, other
+// VerifyProof options, regardless of UseProfile position.
+func UseProfile(name string) VerifyOption {
+	return func(opts *VerifyOptions) {
+		opts.profile = name
+	}
+}
+
+// Profiles returns sorted names of the registered profiles
+func (v *Verifier) Profiles() []string {
+	names := make([]string, 0, len(v.opts.profiles))
+	for name := range v.opts.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveOptions merges the Verifier options with the selected profile and the
+// local ones
+func (v *Verifier) resolveOptions(options ...VerifyOption) (VerifyOptions, error) {
+	opts := mergeOptions(false, v.opts, options...)
+	if opts.profile == "" {
+		return opts, nil
+	}
+
+	profile, ok := v.opts.profiles[opts.profile]
+	if !ok {
+		return opts, fmt.Errorf("%w %q", ErrUnknownProfile, opts.profile)
+	}
+
+	opts = mergeOptions(false, v.opts, profile...)
+	return mergeOptions(false, opts, options...), nil
+}
+
+// validateProfiles checks the options of each profile
+func (o *VerifyOptions) validateProfiles() error {
+	for name, profile := range o.profiles {
+		opts := mergeOptions(false, *o, profile...)
+		if opts.verificationKeyFile != o.verificationKeyFile {
+			return fmt.Errorf("profile %q: verification key can't be changed in profile", name)
+		}
+		if opts.proofType != o.proofType {
+			return fmt.Errorf("profile %q: proof type can't be changed in profile", name)
+		}
+		if err := opts.validate(); err != nil {
+			return fmt.Errorf("profile %q: %w", name, err)
+		}
+	}
+	return nil
+}
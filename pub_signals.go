@@ -1,5 +1,7 @@
 package zkverifier_kit
 
+import "fmt"
+
 type (
 	// proofType defines public signals, their indexes and verification rules in ZKP
 	proofType int
@@ -36,8 +38,36 @@ const (
 
 	ParticipationEventID
 	NullifiersTreeRoot
+
+	// new identifiers are appended to keep the values of existing ones
+	BirthdateLowerBound
+	CitizenshipMask
 )
 
+var proofTypeNames = map[proofType]string{
+	GlobalPassport:    "global_passport",
+	GeorgianPassport:  "georgian_passport",
+	PollParticipation: "poll_participation",
+}
+
+// String returns snake_case name of the proof type
+func (t proofType) String() string {
+	if name, ok := proofTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("proof_type_%d", int(t))
+}
+
+// ParseProofType returns the proof type by its name, see proofType.String
+func ParseProofType(name string) (proofType, error) {
+	for t, n := range proofTypeNames {
+		if n == name {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown proof type %q", name)
+}
+
 var (
 	pubGlobalPassport = map[pubSignalID]int{
 		Nullifier:                 0,
@@ -51,8 +81,10 @@ var (
 		CurrentDate:               13,
 		TimestampUpperBound:       15,
 		IdentityCounterUpperBound: 17,
+		BirthdateLowerBound:       18,
 		BirthdateUpperBound:       19,
 		ExpirationDateLowerBound:  20,
+		CitizenshipMask:           22,
 	}
 	pubGeorgianPassport = map[pubSignalID]int{
 		Nullifier:                 0,
@@ -68,8 +100,10 @@ var (
 		CurrentDate:               14,
 		TimestampUpperBound:       16,
 		IdentityCounterUpperBound: 18,
+		BirthdateLowerBound:       19,
 		BirthdateUpperBound:       20,
 		ExpirationDateLowerBound:  21,
+		CitizenshipMask:           23,
 	}
 	pubPollParticipation = map[pubSignalID]int{
 		Nullifier:            0,
@@ -79,6 +113,42 @@ var (
 	}
 )
 
+var pubSignalNames = map[pubSignalID]string{
+	Nullifier:                 "nullifier",
+	BirthDate:                 "birth_date",
+	ExpirationDate:            "expiration_date",
+	Citizenship:               "citizenship",
+	EventID:                   "event_id",
+	EventData:                 "event_data",
+	IdStateRoot:               "id_state_root",
+	Selector:                  "selector",
+	TimestampUpperBound:       "timestamp_upper_bound",
+	IdentityCounterUpperBound: "identity_counter_upper_bound",
+	BirthdateLowerBound:       "birth_date_lower_bound",
+	BirthdateUpperBound:       "birth_date_upper_bound",
+	ExpirationDateLowerBound:  "expiration_date_lower_bound",
+	CitizenshipMask:           "citizenship_mask",
+	PersonalNumberHash:        "personal_number_hash",
+	DocumentType:              "document_type",
+	CurrentDate:               "current_date",
+	ParticipationEventID:      "participation_event_id",
+	NullifiersTreeRoot:        "nullifiers_tree_root",
+}
+
+// String returns snake_case name of the signal, which is used in validation
+// errors keys, e.g. "pub_signals/event_id"
+func (id pubSignalID) String() string {
+	if name, ok := pubSignalNames[id]; ok {
+		return name
+	}
+	return fmt.Sprintf("pub_signal_%d", int(id))
+}
+
+// errKey returns the key of validation errors for the signal
+func (id pubSignalID) errKey() string {
+	return "pub_signals/" + id.String()
+}
+
 // PubSignalGetter is a structure to extract public signals from abstract ZKP in
 // a convenient way. It is an alternative for Indexes to initialize once and
 // reuse for the same proof type and signals.
@@ -1,6 +1,8 @@
 package root
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -14,7 +16,9 @@ const baseTimeout = 5 * time.Second
 // VerifierProvider provides a Verifier based on the given VerifierType from
 // config map.
 //
-// Specifying "disabled: true" in config allows to skip other map fields.
+// Specifying "disabled: true" in config allows to skip other map fields. For
+// PoseidonSMT "latest_root: true" or "root_window: 10m" restrict the accepted
+// roots, see PoseidonSMTVerifier.WithLatestRoot and WithRootWindow.
 type VerifierProvider interface {
 	ProvideVerifier() Verifier
 }
@@ -27,8 +31,8 @@ type config struct {
 
 // NewVerifierProvider creates a new provider with given VerifierType. You must
 // specify the name equal to VerifierType in map: this allows to have multiple
-// verifiers in the same app. For custom name or logic write your own config map
-// handler.
+// verifiers in the same app. For custom name use NewVerifierFromConfig, for
+// custom logic write your own config map handler.
 func NewVerifierProvider(getter kv.Getter, typ VerifierType) VerifierProvider {
 	switch typ {
 	case PoseidonSMT, ProposalSMT:
@@ -40,50 +44,93 @@ func NewVerifierProvider(getter kv.Getter, typ VerifierType) VerifierProvider {
 
 func (c *config) ProvideVerifier() Verifier {
 	return c.once.Do(func() interface{} {
-		var disabled struct {
-			Disabled bool `fig:"disabled"`
-		}
-
-		err := figure.Out(&disabled).
-			From(kv.MustGetStringMap(c.getter, string(c.typ))).
-			Please()
+		v, err := NewVerifierFromConfig(c.getter, string(c.typ), c.typ)
 		if err != nil {
-			panic(fmt.Errorf("failed to figure out %s disabled field: %w", c.typ, err))
-		}
-		if disabled.Disabled {
-			return DisabledVerifier{}
+			panic(err)
 		}
+		return v
+	}).(Verifier)
+}
 
-		var cfg struct {
-			RPC            string        `fig:"rpc,required"`
-			Contract       string        `fig:"contract"`
-			RequestTimeout time.Duration `fig:"request_timeout"`
-		}
+// NewVerifierFromConfig creates a Verifier of the given type from the config
+// map under the key, which has the same fields as the VerifierProvider one. It
+// returns error instead of panic and allows the key to differ from the type,
+// e.g. to have several verifiers of the same type.
+func NewVerifierFromConfig(getter kv.Getter, key string, typ VerifierType) (Verifier, error) {
+	switch typ {
+	case PoseidonSMT, ProposalSMT:
+	default:
+		return nil, fmt.Errorf("unsupported verifier type: %s", typ)
+	}
 
-		err = figure.Out(&cfg).
-			With(figure.EthereumHooks).
-			From(kv.MustGetStringMap(c.getter, string(c.typ))).
-			Please()
-		if err != nil {
-			panic(fmt.Errorf("failed to figure out %s: %w", c.typ, err))
-		}
+	raw, err := getter.GetStringMap(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s config map: %w", key, err)
+	}
 
-		if cfg.RequestTimeout == 0 {
-			cfg.RequestTimeout = baseTimeout
-		}
+	var disabled struct {
+		Disabled bool `fig:"disabled"`
+	}
 
-		var v Verifier
-		switch c.typ {
-		case PoseidonSMT:
-			v, err = NewPoseidonSMTVerifier(cfg.RPC, cfg.Contract, cfg.RequestTimeout)
-		case ProposalSMT:
-			v = NewProposalSMTVerifier(cfg.RPC, cfg.RequestTimeout).WithContract(cfg.Contract)
-		}
+	err = figure.Out(&disabled).
+		From(raw).
+		Please()
+	if err != nil {
+		return nil, fmt.Errorf("failed to figure out %s disabled field: %w", key, err)
+	}
+	if disabled.Disabled {
+		return DisabledVerifier{}, nil
+	}
 
-		if err != nil {
-			panic(fmt.Errorf("failed to create %s verifier: %w", c.typ, err))
-		}
+	var cfg struct {
+		RPC            string        `fig:"rpc,required"`
+		Contract       string        `fig:"contract"`
+		RequestTimeout time.Duration `fig:"request_timeout"`
+		LatestRoot     bool          `fig:"latest_root"`
+		RootWindow     time.Duration `fig:"root_window"`
+	}
 
-		return v
-	}).(Verifier)
+	err = figure.Out(&cfg).
+		With(figure.EthereumHooks).
+		From(raw).
+		Please()
+	if err != nil {
+		return nil, fmt.Errorf("failed to figure out %s: %w", key, err)
+	}
+
+	if cfg.RequestTimeout == 0 {
+		cfg.RequestTimeout = baseTimeout
+	}
+
+	var v Verifier
+	switch typ {
+	case PoseidonSMT:
+		v, err = newPoseidonSMTVerifier(cfg.RPC, cfg.Contract, cfg.RequestTimeout, cfg.LatestRoot, cfg.RootWindow)
+	case ProposalSMT:
+		v = NewProposalSMTVerifier(cfg.RPC, cfg.RequestTimeout).WithContract(cfg.Contract)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s verifier: %w", typ, err)
+	}
+
+	return v, nil
+}
+
+func newPoseidonSMTVerifier(rpc, contract string, timeout time.Duration, latest bool, window time.Duration) (*PoseidonSMTVerifier, error) {
+	v, err := NewPoseidonSMTVerifier(rpc, contract, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case latest && window != 0:
+		return nil, errors.New("latest_root and root_window are mutually exclusive")
+	case latest:
+		return v.WithLatestRoot(), nil
+	case window != 0:
+		return v.WithRootWindow(context.Background(), window)
+	}
+
+	return v, nil
 }
@@ -0,0 +1,49 @@
+package root
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const tracerName = "github.com/rarimo/zkverifier-kit/root"
+
+// AttrVerifierType is the span attribute with the root verifier type
+const AttrVerifierType = attribute.Key("zkverifier.root_verifier")
+
+// ContextVerifier is a Verifier, which accepts the context for RPC calls and
+// tracing spans. The verifiers of this package implement it.
+type ContextVerifier interface {
+	Verifier
+	VerifyRootContext(ctx context.Context, root string) error
+}
+
+// VerifyContext calls VerifyRootContext when the verifier supports it, and
+// VerifyRoot otherwise
+func VerifyContext(ctx context.Context, v Verifier, root string) error {
+	if cv, ok := v.(ContextVerifier); ok {
+		return cv.VerifyRootContext(ctx, root)
+	}
+	return v.VerifyRoot(root)
+}
+
+func newTracer(tp trace.TracerProvider) trace.Tracer {
+	if tp == nil {
+		tp = noop.NewTracerProvider()
+	}
+	return tp.Tracer(tracerName)
+}
+
+// endSpan ends the span of root verification. The error message is not
+// recorded, because it may contain RPC URL with credentials.
+func endSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, ErrInvalidRoot) {
+		span.SetStatus(codes.Error, "root verification failed")
+	}
+	span.SetAttributes(attribute.Bool("zkverifier.root_valid", err == nil))
+	span.End()
+}
@@ -27,6 +27,21 @@ const (
 	ProposalSMT              = "proposalsmt_root_verifier"
 )
 
+// TypedVerifier is implemented by the verifiers of known VerifierType and by
+// the wrappers forwarding the type of the wrapped verifier
+type TypedVerifier interface {
+	Type() VerifierType
+}
+
+// TypeOf returns the VerifierType of v, or its Go type name when v doesn't
+// implement TypedVerifier
+func TypeOf(v Verifier) VerifierType {
+	if tv, ok := v.(TypedVerifier); ok {
+		return tv.Type()
+	}
+	return VerifierType(fmt.Sprintf("%T", v))
+}
+
 func decimalTo32Bytes(root string) *[32]byte {
 	b, ok := new(big.Int).SetString(root, 10)
 	if !ok {
@@ -0,0 +1,42 @@
+package root
+
+import (
+	"context"
+	"time"
+)
+
+// Observer receives metrics of root verification calls. It is called
+// synchronously, so the implementation must be fast and safe for concurrent use.
+type Observer interface {
+	// ObserveRootCall reports the call duration and its result: nil,
+	// ErrInvalidRoot or other error on RPC failure
+	ObserveRootCall(typ VerifierType, d time.Duration, err error)
+}
+
+type observedVerifier struct {
+	Verifier
+	typ      VerifierType
+	observer Observer
+}
+
+// NewObservedVerifier wraps the verifier to report each VerifyRoot call to the
+// observer. Type is used to distinguish the verifiers in metrics.
+func NewObservedVerifier(v Verifier, typ VerifierType, o Observer) Verifier {
+	return &observedVerifier{Verifier: v, typ: typ, observer: o}
+}
+
+func (v *observedVerifier) VerifyRoot(root string) error {
+	return v.VerifyRootContext(context.Background(), root)
+}
+
+func (v *observedVerifier) VerifyRootContext(ctx context.Context, root string) error {
+	start := time.Now()
+	err := VerifyContext(ctx, v.Verifier, root)
+	v.observer.ObserveRootCall(v.typ, time.Since(start), err)
+	return err
+}
+
+// Type returns the type given to NewObservedVerifier
+func (v *observedVerifier) Type() VerifierType {
+	return v.typ
+}
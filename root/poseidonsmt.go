@@ -2,19 +2,39 @@ package root
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/big"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/rarimo/zkverifier-kit/internal/poseidonsmt"
+	"github.com/rarimo/zkverifier-kit/smt"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // PoseidonSMTVerifier is a wrapper around PoseidonSMT binding which calls
 // IsRootValid on the contract. Currently used for GlobalPassport and
 // GeorgianPassport proof types.
+//
+// By default, any root valid by the contract is accepted, which includes roots
+// replaced less than ROOT_VALIDITY ago. Use WithLatestRoot or WithRootWindow to
+// reject proofs built on the stale identity state.
 type PoseidonSMTVerifier struct {
 	caller  *poseidonsmt.PoseidonSMTCaller
+	headers headerReader
 	timeout time.Duration
+	tracer  trace.Tracer
+	latest  bool
+	window  time.Duration
+	// windowStart is shared by the copies with the same window
+	windowStart *windowStart
+}
+
+type headerReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
 }
 
 func NewPoseidonSMTVerifier(rpcURL, contract string, timeout time.Duration) (*PoseidonSMTVerifier, error) {
@@ -28,16 +48,88 @@ func NewPoseidonSMTVerifier(rpcURL, contract string, timeout time.Duration) (*Po
 		return nil, fmt.Errorf("failed to bind PoseidonSMT caller: %w", err)
 	}
 
-	return &PoseidonSMTVerifier{caller: caller, timeout: timeout}, nil
+	return &PoseidonSMTVerifier{
+		caller:  caller,
+		headers: cli,
+		timeout: timeout,
+		tracer:  newTracer(nil),
+	}, nil
+}
+
+// WithTracerProvider returns new instance of PoseidonSMTVerifier, which
+// creates tracing spans for contract calls
+func (v *PoseidonSMTVerifier) WithTracerProvider(tp trace.TracerProvider) *PoseidonSMTVerifier {
+	c := *v
+	c.tracer = newTracer(tp)
+	return &c
+}
+
+// WithLatestRoot returns new instance of PoseidonSMTVerifier, which accepts
+// only the latest root of the tree, checked with IsRootLatest. Proof becomes
+// invalid as soon as any identity is registered or revoked, so clients must be
+// ready to regenerate it.
+func (v *PoseidonSMTVerifier) WithLatestRoot() *PoseidonSMTVerifier {
+	c := *v
+	c.latest = true
+	c.window = 0
+	c.windowStart = nil
+	return &c
+}
+
+// WithRootWindow returns new instance of PoseidonSMTVerifier, which accepts
+// the latest root and the roots replaced less than window ago. The window must
+// be shorter than ROOT_VALIDITY of the contract, which is fetched to check it.
+//
+// Replacement time is found by the contract state at the first block not older
+// than window, so the RPC must serve the state of that block: for long windows
+// an archive node may be required. Due to the block time the effective window
+// may be a bit shorter, but never longer. The first block of the window is
+// found once per block with a few header requests, the other calls make one
+// request of the latest header and two contract calls at the found block.
+func (v *PoseidonSMTVerifier) WithRootWindow(ctx context.Context, window time.Duration) (*PoseidonSMTVerifier, error) {
+	if window < time.Second {
+		return nil, fmt.Errorf("root window %s is shorter than 1s", window)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+
+	validity, err := v.caller.ROOTVALIDITY(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return nil, fmt.Errorf("call ROOT_VALIDITY on PoseidonSMT: %w", err)
+	}
+	if !validity.IsInt64() || window >= time.Duration(validity.Int64())*time.Second {
+		return nil, fmt.Errorf("root window %s is not shorter than ROOT_VALIDITY %ss", window, validity)
+	}
+
+	c := *v
+	c.latest = false
+	c.window = window
+	c.windowStart = new(windowStart)
+	return &c, nil
+}
+
+func (v *PoseidonSMTVerifier) Type() VerifierType {
+	return PoseidonSMT
 }
 
 func (v *PoseidonSMTVerifier) VerifyRoot(root string) error {
+	return v.VerifyRootContext(context.Background(), root)
+}
+
+func (v *PoseidonSMTVerifier) VerifyRootContext(ctx context.Context, root string) (err error) {
+	ctx, span := v.tracer.Start(ctx, "PoseidonSMT.IsRootValid",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(AttrVerifierType.String(string(PoseidonSMT))),
+	)
+	defer func() { endSpan(span, err) }()
+
 	bytes := decimalTo32Bytes(root)
 	if bytes == nil {
 		return ErrInvalidRoot
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), v.timeout)
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
 	defer cancel()
 
 	valid, err := v.caller.IsRootValid(&bind.CallOpts{Context: ctx}, *bytes)
@@ -49,5 +141,156 @@ func (v *PoseidonSMTVerifier) VerifyRoot(root string) error {
 		return ErrInvalidRoot
 	}
 
+	if !v.latest && v.window == 0 {
+		return nil
+	}
+
+	latest, err := v.caller.IsRootLatest(&bind.CallOpts{Context: ctx}, *bytes)
+	if err != nil {
+		return fmt.Errorf("call IsRootLatest on PoseidonSMT: %w", err)
+	}
+
+	switch {
+	case latest:
+		return nil
+	case v.latest:
+		return ErrInvalidRoot
+	}
+
+	return v.verifyWindow(ctx, *bytes)
+}
+
+// verifyWindow checks the root, which is valid, but not the latest one, to be
+// replaced within the window. At the first block of the window such root was
+// either the latest one, or not yet added (invalid), otherwise it had been
+// replaced before.
+func (v *PoseidonSMTVerifier) verifyWindow(ctx context.Context, root [32]byte) error {
+	block, err := v.firstBlockSince(ctx, v.window)
+	if err != nil {
+		return fmt.Errorf("find first block of root window: %w", err)
+	}
+
+	opts := &bind.CallOpts{Context: ctx, BlockNumber: block}
+
+	latest, err := v.caller.IsRootLatest(opts, root)
+	if err != nil {
+		return fmt.Errorf("call IsRootLatest on PoseidonSMT at block %s: %w", block, err)
+	}
+	if latest {
+		return nil
+	}
+
+	valid, err := v.caller.IsRootValid(opts, root)
+	if err != nil {
+		return fmt.Errorf("call IsRootValid on PoseidonSMT at block %s: %w", block, err)
+	}
+	if valid {
+		return ErrInvalidRoot
+	}
+
 	return nil
 }
+
+// windowStart caches the first block of the root window for the latest block,
+// so the search runs once per block instead of each VerifyRoot call
+type windowStart struct {
+	mu sync.Mutex
+	// head is the latest block number the start is found for
+	head *big.Int
+	// start is the first block of the window
+	start *big.Int
+	// before is the last block before the window, it is the lower bound of the
+	// next search, because the window only moves forward
+	before *types.Header
+}
+
+// firstBlockSince finds the first block with timestamp not earlier than the
+// latest block timestamp minus d. Timestamps grow at least by 1 second per
+// block, which gives the lower bound for the first search. The result is cached
+// until the next block.
+func (v *PoseidonSMTVerifier) firstBlockSince(ctx context.Context, d time.Duration) (*big.Int, error) {
+	hi, err := v.headers.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get latest header: %w", err)
+	}
+
+	c := v.windowStart
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.head != nil && c.head.Cmp(hi.Number) == 0 {
+		return c.start, nil
+	}
+
+	secs := uint64(d / time.Second)
+	if hi.Time < secs || hi.Number.Uint64() < secs {
+		return nil, errors.New("chain is younger than the window")
+	}
+	target := hi.Time - secs
+	head := hi.Number
+
+	lo := c.before
+	if lo == nil || lo.Time >= target || lo.Number.Cmp(head) >= 0 {
+		lo, err = v.headers.HeaderByNumber(ctx, new(big.Int).Sub(head, new(big.Int).SetUint64(secs)))
+		if err != nil {
+			return nil, fmt.Errorf("get header: %w", err)
+		}
+		if lo.Time >= target {
+			c.head, c.start, c.before = head, lo.Number, nil
+			return lo.Number, nil
+		}
+	}
+
+	// invariant: lo.Time < target <= hi.Time; interpolation is fast for regular
+	// block time, while alternating it with bisection bounds the worst case
+	for i := 0; new(big.Int).Sub(hi.Number, lo.Number).Cmp(big.NewInt(1)) > 0; i++ {
+		span := new(big.Int).Sub(hi.Number, lo.Number)
+		step := new(big.Int).Rsh(span, 1)
+		if i%2 == 0 {
+			step.Mul(span, new(big.Int).SetUint64(target-lo.Time))
+			step.Div(step, new(big.Int).SetUint64(hi.Time-lo.Time))
+		}
+		if step.Sign() == 0 {
+			step.SetInt64(1)
+		} else if step.Cmp(span) >= 0 {
+			step.Sub(span, big.NewInt(1))
+		}
+
+		mid, err := v.headers.HeaderByNumber(ctx, step.Add(lo.Number, step))
+		if err != nil {
+			return nil, fmt.Errorf("get header: %w", err)
+		}
+
+		if mid.Time < target {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	c.head, c.start, c.before = head, hi.Number, lo
+	return hi.Number, nil
+}
+
+// GetProof fetches the proof of the key from the contract and verifies it
+// locally, so the response can't be forged without the root. Check the proof
+// root with VerifyRoot or smt.Proof.VerifyRoot, unless it is already trusted.
+func (v *PoseidonSMTVerifier) GetProof(ctx context.Context, key [32]byte) (smt.Proof, error) {
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+
+	resp, err := v.caller.GetProof(&bind.CallOpts{Context: ctx}, key)
+	if err != nil {
+		return smt.Proof{}, fmt.Errorf("call GetProof on PoseidonSMT: %w", err)
+	}
+
+	proof := smt.Proof(resp)
+	if proof.Key != key {
+		return proof, fmt.Errorf("%w: proof is for another key %x", smt.ErrInvalidProof, proof.Key)
+	}
+	if err = proof.Verify(); err != nil {
+		return proof, fmt.Errorf("verify proof: %w", err)
+	}
+
+	return proof, nil
+}
@@ -0,0 +1,177 @@
+package root
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/rarimo/zkverifier-kit/internal/poseidonsmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRootValidity = 3600
+
+// fakeChain serves PoseidonSMT calls and headers of the chain with block time
+// of 5 seconds, where root i is the latest one from the block rootBlocks[i]
+type fakeChain struct {
+	abi        *abi.ABI
+	rootBlocks []uint64
+	head       uint64
+	// headerCalls counts HeaderByNumber requests
+	headerCalls int
+}
+
+func (c *fakeChain) time(block uint64) uint64 {
+	return 1_700_000_000 + block*5
+}
+
+func (c *fakeChain) HeaderByNumber(_ context.Context, number *big.Int) (*types.Header, error) {
+	c.headerCalls++
+	n := c.head
+	if number != nil {
+		n = number.Uint64()
+	}
+	if n > c.head {
+		return nil, errors.New("block not found")
+	}
+	return &types.Header{Number: new(big.Int).SetUint64(n), Time: c.time(n)}, nil
+}
+
+func (c *fakeChain) CodeAt(context.Context, common.Address, *big.Int) ([]byte, error) {
+	return []byte{1}, nil
+}
+
+func (c *fakeChain) CallContract(_ context.Context, call ethereum.CallMsg, number *big.Int) ([]byte, error) {
+	block := c.head
+	if number != nil {
+		block = number.Uint64()
+	}
+
+	method, err := c.abi.MethodById(call.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	if method.Name == "ROOT_VALIDITY" {
+		return method.Outputs.Pack(big.NewInt(testRootValidity))
+	}
+
+	args, err := method.Inputs.Unpack(call.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	root := args[0].([32]byte)
+	i := int(new(big.Int).SetBytes(root[:]).Int64())
+
+	var res bool
+	if i < len(c.rootBlocks) && c.rootBlocks[i] <= block {
+		latest := i == len(c.rootBlocks)-1 || c.rootBlocks[i+1] > block
+		switch method.Name {
+		case "isRootLatest":
+			res = latest
+		case "isRootValid":
+			res = latest || c.time(c.rootBlocks[i+1])+testRootValidity > c.time(block)
+		}
+	}
+
+	return method.Outputs.Pack(res)
+}
+
+func TestPoseidonSMTVerifierPolicies(t *testing.T) {
+	a, err := poseidonsmt.PoseidonSMTMetaData.GetAbi()
+	require.NoError(t, err)
+
+	// root 1 is replaced 30 minutes ago, root 2 is replaced 5 minutes ago, and
+	// root 0 is expired by the contract
+	chain := &fakeChain{abi: a, rootBlocks: []uint64{1, 10_000, 10_000 + 300, 10_000 + 660}, head: 10_000 + 720}
+	caller, err := poseidonsmt.NewPoseidonSMTCaller(common.Address{}, chain)
+	require.NoError(t, err)
+
+	v := &PoseidonSMTVerifier{caller: caller, headers: chain, timeout: time.Second, tracer: newTracer(nil)}
+
+	assert.ErrorIs(t, v.VerifyRoot("0"), ErrInvalidRoot)
+	assert.NoError(t, v.VerifyRoot("1"))
+	assert.NoError(t, v.VerifyRoot("2"))
+	assert.NoError(t, v.VerifyRoot("3"))
+	assert.ErrorIs(t, v.VerifyRoot("4"), ErrInvalidRoot)
+
+	latest := v.WithLatestRoot()
+	assert.ErrorIs(t, latest.VerifyRoot("1"), ErrInvalidRoot)
+	assert.ErrorIs(t, latest.VerifyRoot("2"), ErrInvalidRoot)
+	assert.NoError(t, latest.VerifyRoot("3"))
+
+	_, err = v.WithRootWindow(context.Background(), time.Hour)
+	assert.Error(t, err)
+
+	windowed, err := v.WithRootWindow(context.Background(), 10*time.Minute)
+	require.NoError(t, err)
+	assert.ErrorIs(t, windowed.VerifyRoot("0"), ErrInvalidRoot)
+	assert.ErrorIs(t, windowed.VerifyRoot("1"), ErrInvalidRoot)
+	assert.NoError(t, windowed.VerifyRoot("2"))
+	assert.NoError(t, windowed.VerifyRoot("3"))
+
+	// root 3 was added and replaced within the window
+	chain.rootBlocks = []uint64{1, 10_000, 10_000 + 300, 10_000 + 650, 10_000 + 660}
+	assert.ErrorIs(t, windowed.VerifyRoot("1"), ErrInvalidRoot)
+	assert.NoError(t, windowed.VerifyRoot("2"))
+	assert.NoError(t, windowed.VerifyRoot("3"))
+	assert.NoError(t, windowed.VerifyRoot("4"))
+}
+
+func TestFirstBlockSince(t *testing.T) {
+	chain := &fakeChain{head: 100_000}
+
+	for _, d := range []time.Duration{time.Second, 5 * time.Second, 7 * time.Second, time.Hour, 24 * time.Hour} {
+		v := &PoseidonSMTVerifier{headers: chain, windowStart: new(windowStart)}
+		block, err := v.firstBlockSince(context.Background(), d)
+		require.NoError(t, err)
+
+		target := chain.time(chain.head) - uint64(d/time.Second)
+		n := block.Uint64()
+		assert.GreaterOrEqual(t, chain.time(n), target, d)
+		assert.Less(t, chain.time(n-1), target, d)
+	}
+
+	v := &PoseidonSMTVerifier{headers: chain, windowStart: new(windowStart)}
+	_, err := v.firstBlockSince(context.Background(), 24*time.Hour*365*100)
+	assert.Error(t, err)
+}
+
+func TestFirstBlockSinceCache(t *testing.T) {
+	chain := &fakeChain{head: 100_000}
+	v := &PoseidonSMTVerifier{headers: chain, windowStart: new(windowStart)}
+
+	first, err := v.firstBlockSince(context.Background(), time.Hour)
+	require.NoError(t, err)
+	searchCalls := chain.headerCalls
+
+	// the same block: only the latest header is requested
+	for i := 0; i < 10; i++ {
+		block, err := v.firstBlockSince(context.Background(), time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, first, block)
+	}
+	assert.Equal(t, searchCalls+10, chain.headerCalls)
+
+	// the next block: the search starts from the previous window start
+	chain.head++
+	chain.headerCalls = 0
+	block, err := v.firstBlockSince(context.Background(), time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, first.Uint64()+1, block.Uint64())
+	assert.LessOrEqual(t, chain.headerCalls, 3)
+}
+
+func TestTypeOf(t *testing.T) {
+	v := &PoseidonSMTVerifier{}
+	assert.Equal(t, PoseidonSMT, TypeOf(v))
+	assert.Equal(t, PoseidonSMT, TypeOf(NewObservedVerifier(v, PoseidonSMT, nil)))
+	assert.Equal(t, VerifierType(ProposalSMT), TypeOf(NewProposalSMTVerifier("", time.Second)))
+	assert.Equal(t, VerifierType("root.DisabledVerifier"), TypeOf(DisabledVerifier{}))
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/rarimo/zkverifier-kit/internal/proposalsmt"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ProposalSMTVerifier performs validation with filtering RootUpdated events of
@@ -16,6 +17,7 @@ type ProposalSMTVerifier struct {
 	rpc     string
 	addr    string
 	timeout time.Duration
+	tracer  trace.Tracer
 }
 
 // NewProposalSMTVerifier creates basic ProposalSMTVerifier with RPC only. You
@@ -24,6 +26,7 @@ func NewProposalSMTVerifier(rpcURL string, timeout time.Duration) *ProposalSMTVe
 	return &ProposalSMTVerifier{
 		rpc:     rpcURL,
 		timeout: timeout,
+		tracer:  newTracer(nil),
 	}
 }
 
@@ -34,10 +37,36 @@ func (v *ProposalSMTVerifier) WithContract(addr string) Verifier {
 		rpc:     v.rpc,
 		addr:    addr,
 		timeout: v.timeout,
+		tracer:  v.tracer,
 	}
 }
 
+// WithTracerProvider returns new instance of ProposalSMTVerifier, which
+// creates tracing spans for contract calls
+func (v *ProposalSMTVerifier) WithTracerProvider(tp trace.TracerProvider) *ProposalSMTVerifier {
+	return &ProposalSMTVerifier{
+		rpc:     v.rpc,
+		addr:    v.addr,
+		timeout: v.timeout,
+		tracer:  newTracer(tp),
+	}
+}
+
+func (v *ProposalSMTVerifier) Type() VerifierType {
+	return ProposalSMT
+}
+
 func (v *ProposalSMTVerifier) VerifyRoot(root string) error {
+	return v.VerifyRootContext(context.Background(), root)
+}
+
+func (v *ProposalSMTVerifier) VerifyRootContext(ctx context.Context, root string) (err error) {
+	ctx, span := v.tracer.Start(ctx, "ProposalSMT.FilterRootUpdated",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(AttrVerifierType.String(ProposalSMT)),
+	)
+	defer func() { endSpan(span, err) }()
+
 	cli, addr, err := prepareBindingData(v.rpc, v.addr)
 	if err != nil {
 		return fmt.Errorf("failed to prepare binding data: %w", err)
@@ -48,7 +77,7 @@ func (v *ProposalSMTVerifier) VerifyRoot(root string) error {
 		return ErrInvalidRoot
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), v.timeout)
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
 	defer cancel()
 
 	filter, err := proposalsmt.NewProposalSMTFilterer(addr, cli)
@@ -0,0 +1,46 @@
+package root
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rarimo/zkverifier-kit/smt"
+)
+
+// SMTVerifier verifies the root against the history of local smt.Tree, like
+// IsRootValid of the contracts: the current root is valid, and the replaced
+// one stays valid during the validity period.
+type SMTVerifier struct {
+	tree     *smt.Tree
+	validity time.Duration
+	now      func() time.Time
+}
+
+// NewSMTVerifier creates the verifier for the tree. Zero validity accepts the
+// current root only.
+func NewSMTVerifier(tree *smt.Tree, validity time.Duration) *SMTVerifier {
+	return &SMTVerifier{tree: tree, validity: validity, now: time.Now}
+}
+
+func (v *SMTVerifier) VerifyRoot(root string) error {
+	bytes := decimalTo32Bytes(root)
+	if bytes == nil || *bytes == [32]byte{} {
+		return ErrInvalidRoot
+	}
+
+	info, ok, err := v.tree.RootInfo(*bytes)
+	if err != nil {
+		return fmt.Errorf("get root info: %w", err)
+	}
+
+	switch {
+	case !ok:
+		return ErrInvalidRoot
+	case info.ReplacedAt.IsZero():
+		return nil
+	case v.now().Sub(info.ReplacedAt) >= v.validity:
+		return ErrInvalidRoot
+	}
+
+	return nil
+}
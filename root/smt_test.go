@@ -0,0 +1,42 @@
+package root
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/rarimo/zkverifier-kit/smt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSMTVerifier(t *testing.T) {
+	tree, err := smt.NewMemoryTree(80)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var roots []string
+	for i := int64(1); i <= 2; i++ {
+		var key [32]byte
+		big.NewInt(i).FillBytes(key[:])
+		if err = tree.Add(key, key); err != nil {
+			t.Fatal(err)
+		}
+
+		root := tree.Root()
+		roots = append(roots, new(big.Int).SetBytes(root[:]).String())
+	}
+
+	v := NewSMTVerifier(tree, time.Hour)
+	assert.NoError(t, v.VerifyRoot(roots[1]))
+	assert.NoError(t, v.VerifyRoot(roots[0]))
+	assert.ErrorIs(t, v.VerifyRoot("0"), ErrInvalidRoot)
+	assert.ErrorIs(t, v.VerifyRoot("12345"), ErrInvalidRoot)
+	assert.ErrorIs(t, v.VerifyRoot("not a number"), ErrInvalidRoot)
+
+	v.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	assert.ErrorIs(t, v.VerifyRoot(roots[0]), ErrInvalidRoot)
+	assert.NoError(t, v.VerifyRoot(roots[1]))
+
+	assert.ErrorIs(t, NewSMTVerifier(tree, 0).VerifyRoot(roots[0]), ErrInvalidRoot)
+}
@@ -0,0 +1,137 @@
+// Package smt implements Poseidon sparse Merkle tree compatible with the
+// SparseMerkleTree library used in PoseidonSMT and ProposalSMT contracts.
+//
+// Leaf hash is Poseidon(key, value, 1), middle node hash is Poseidon(left,
+// right), and the path to the key goes by its bits starting from the least
+// significant one: 0 is left and 1 is right.
+package smt
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/iden3/go-iden3-crypto/poseidon"
+)
+
+// MaxDepth is the maximal depth of the tree, limited by the key size
+const MaxDepth = 256
+
+var (
+	// ErrRootMismatch shows that the proof is well-formed, but the root
+	// computed from it differs from the expected one
+	ErrRootMismatch = errors.New("root mismatch")
+	// ErrInvalidProof shows that the proof is malformed
+	ErrInvalidProof = errors.New("invalid proof")
+)
+
+// Proof is inclusion or exclusion proof of the key. Its layout is the same as
+// of SparseMerkleTreeProof from GetProof methods of PoseidonSMT and
+// ProposalSMT bindings, so you can convert it directly: smt.Proof(proof).
+//
+// Existence shows whether the key is in the tree with Value. Otherwise, the
+// path to the key ends with either empty node or another leaf, which is
+// provided in AuxKey and AuxValue when AuxExistence is true. Siblings are
+// ordered from the root and may be padded with zeros.
+type Proof struct {
+	Root         [32]byte
+	Siblings     [][32]byte
+	Existence    bool
+	Key          [32]byte
+	Value        [32]byte
+	AuxExistence bool
+	AuxKey       [32]byte
+	AuxValue     [32]byte
+}
+
+// HashLeaf returns the leaf node hash. The key and value must be in BN254 field.
+func HashLeaf(key, value *big.Int) (*big.Int, error) {
+	return poseidon.Hash([]*big.Int{key, value, big.NewInt(1)})
+}
+
+// HashNode returns the middle node hash of its children
+func HashNode(left, right *big.Int) (*big.Int, error) {
+	return poseidon.Hash([]*big.Int{left, right})
+}
+
+// VerifyRoot checks that the proof is valid for the trusted root, e.g. the
+// one verified on-chain. Use Existence to distinguish inclusion and exclusion.
+func (p Proof) VerifyRoot(root [32]byte) error {
+	if p.Root != root {
+		return fmt.Errorf("%w: proof root %x, expected %x", ErrRootMismatch, p.Root, root)
+	}
+	return p.Verify()
+}
+
+// Verify checks that the proof is consistent with its own root. This alone
+// doesn't prove anything without checking the root, see VerifyRoot.
+func (p Proof) Verify() error {
+	computed, err := p.ComputeRoot()
+	if err != nil {
+		return err
+	}
+
+	if computed.Cmp(new(big.Int).SetBytes(p.Root[:])) != 0 {
+		return fmt.Errorf("%w: computed %x, proof root %x", ErrRootMismatch, computed, p.Root)
+	}
+
+	return nil
+}
+
+// ComputeRoot returns the root hash from the leaf and siblings of the proof
+func (p Proof) ComputeRoot() (*big.Int, error) {
+	if len(p.Siblings) > MaxDepth {
+		return nil, fmt.Errorf("%w: %d siblings exceed max depth %d", ErrInvalidProof, len(p.Siblings), MaxDepth)
+	}
+
+	// trailing zeros are padding, the last non-zero sibling is the leaf one
+	depth := len(p.Siblings)
+	for depth > 0 && p.Siblings[depth-1] == [32]byte{} {
+		depth--
+	}
+
+	key := new(big.Int).SetBytes(p.Key[:])
+	node := new(big.Int)
+
+	switch {
+	case p.Existence:
+		leaf, err := HashLeaf(key, new(big.Int).SetBytes(p.Value[:]))
+		if err != nil {
+			return nil, fmt.Errorf("%w: hash leaf: %w", ErrInvalidProof, err)
+		}
+		node = leaf
+	case p.AuxExistence:
+		if p.AuxKey == p.Key {
+			return nil, fmt.Errorf("%w: auxiliary key equals the key in exclusion proof", ErrInvalidProof)
+		}
+
+		auxKey := new(big.Int).SetBytes(p.AuxKey[:])
+		for i := 0; i < depth; i++ {
+			if auxKey.Bit(i) != key.Bit(i) {
+				return nil, fmt.Errorf("%w: auxiliary key is not on the key path", ErrInvalidProof)
+			}
+		}
+
+		leaf, err := HashLeaf(auxKey, new(big.Int).SetBytes(p.AuxValue[:]))
+		if err != nil {
+			return nil, fmt.Errorf("%w: hash auxiliary leaf: %w", ErrInvalidProof, err)
+		}
+		node = leaf
+	}
+
+	for i := depth - 1; i >= 0; i-- {
+		sibling := new(big.Int).SetBytes(p.Siblings[i][:])
+
+		var err error
+		if key.Bit(i) == 1 {
+			node, err = HashNode(sibling, node)
+		} else {
+			node, err = HashNode(node, sibling)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: hash node at depth %d: %w", ErrInvalidProof, i, err)
+		}
+	}
+
+	return node, nil
+}
@@ -0,0 +1,173 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/iden3/go-iden3-crypto/poseidon"
+	"github.com/rarimo/zkverifier-kit/internal/poseidonsmt"
+	"github.com/rarimo/zkverifier-kit/internal/proposalsmt"
+	"github.com/stretchr/testify/assert"
+)
+
+// binding proofs must be convertible to Proof
+var (
+	_ = Proof(poseidonsmt.SparseMerkleTreeProof{})
+	_ = Proof(proposalsmt.SparseMerkleTreeProof{})
+)
+
+func bytes32(v *big.Int) (b [32]byte) {
+	v.FillBytes(b[:])
+	return
+}
+
+func must(v *big.Int, err error) *big.Int {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func TestHashKnownAnswers(t *testing.T) {
+	// Poseidon test vectors of circomlibjs, the contracts use the same
+	// circomlib-compatible hash for 2 and 3 inputs
+	assert.Equal(t,
+		"7853200120776062878684798364095072458815029376092732009249414926327459813530",
+		must(HashNode(big.NewInt(1), big.NewInt(2))).String())
+	assert.Equal(t,
+		"6542985608222806190361240322586112750744169038454362455181422643027100751666",
+		must(poseidon.Hash([]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)})).String())
+
+	// leaf is Poseidon(key, value, 1)
+	assert.Equal(t,
+		must(poseidon.Hash([]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(1)})),
+		must(HashLeaf(big.NewInt(1), big.NewInt(2))))
+	assert.Equal(t,
+		"13578938674299138072471463694055224830892726234048532520316387704878000008795",
+		must(HashLeaf(big.NewInt(1), big.NewInt(2))).String())
+}
+
+func TestProofVerify(t *testing.T) {
+	// keys 1 and 2 differ in the first bit: 2 goes left, 1 goes right
+	var (
+		leaf1 = must(HashLeaf(big.NewInt(1), big.NewInt(100)))
+		leaf2 = must(HashLeaf(big.NewInt(2), big.NewInt(200)))
+		root  = bytes32(must(HashNode(leaf2, leaf1)))
+		pad   = make([][32]byte, 3)
+	)
+
+	testCases := []struct {
+		name  string
+		proof Proof
+		err   error
+	}{
+		{
+			name: "Inclusion",
+			proof: Proof{
+				Root:      root,
+				Siblings:  append([][32]byte{bytes32(leaf2)}, pad...),
+				Existence: true,
+				Key:       bytes32(big.NewInt(1)),
+				Value:     bytes32(big.NewInt(100)),
+			},
+		},
+		{
+			name: "Inclusion of the left leaf",
+			proof: Proof{
+				Root:      root,
+				Siblings:  [][32]byte{bytes32(leaf1)},
+				Existence: true,
+				Key:       bytes32(big.NewInt(2)),
+				Value:     bytes32(big.NewInt(200)),
+			},
+		},
+		{
+			name: "Exclusion with auxiliary leaf",
+			proof: Proof{
+				Root:         root,
+				Siblings:     append([][32]byte{bytes32(leaf2)}, pad...),
+				Key:          bytes32(big.NewInt(3)),
+				AuxExistence: true,
+				AuxKey:       bytes32(big.NewInt(1)),
+				AuxValue:     bytes32(big.NewInt(100)),
+			},
+		},
+		{
+			name: "Exclusion in empty tree",
+			proof: Proof{
+				Siblings: pad,
+				Key:      bytes32(big.NewInt(3)),
+			},
+		},
+		{
+			name: "Inclusion in single leaf tree",
+			proof: Proof{
+				Root:      bytes32(leaf1),
+				Siblings:  pad,
+				Existence: true,
+				Key:       bytes32(big.NewInt(1)),
+				Value:     bytes32(big.NewInt(100)),
+			},
+		},
+		{
+			name: "Wrong value",
+			proof: Proof{
+				Root:      root,
+				Siblings:  [][32]byte{bytes32(leaf2)},
+				Existence: true,
+				Key:       bytes32(big.NewInt(1)),
+				Value:     bytes32(big.NewInt(101)),
+			},
+			err: ErrRootMismatch,
+		},
+		{
+			name: "Auxiliary leaf is the key",
+			proof: Proof{
+				Root:         root,
+				Siblings:     [][32]byte{bytes32(leaf2)},
+				Key:          bytes32(big.NewInt(1)),
+				AuxExistence: true,
+				AuxKey:       bytes32(big.NewInt(1)),
+				AuxValue:     bytes32(big.NewInt(100)),
+			},
+			err: ErrInvalidProof,
+		},
+		{
+			name: "Auxiliary leaf is off the key path",
+			proof: Proof{
+				Root:         root,
+				Siblings:     [][32]byte{bytes32(leaf1)},
+				Key:          bytes32(big.NewInt(4)),
+				AuxExistence: true,
+				AuxKey:       bytes32(big.NewInt(1)),
+				AuxValue:     bytes32(big.NewInt(100)),
+			},
+			err: ErrInvalidProof,
+		},
+		{
+			name: "Value out of field",
+			proof: Proof{
+				Root:      root,
+				Siblings:  [][32]byte{bytes32(leaf2)},
+				Existence: true,
+				Key:       bytes32(big.NewInt(1)),
+				Value:     [32]byte{0xff},
+			},
+			err: ErrInvalidProof,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.proof.VerifyRoot(tc.proof.Root)
+			if tc.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	proof := testCases[0].proof
+	assert.ErrorIs(t, proof.VerifyRoot([32]byte{1}), ErrRootMismatch)
+}
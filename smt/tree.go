@@ -0,0 +1,481 @@
+package smt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/leveldb"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+)
+
+var (
+	ErrKeyExists   = errors.New("key already exists")
+	ErrKeyNotFound = errors.New("key not found")
+	ErrMaxDepth    = errors.New("max depth reached")
+)
+
+// Database is the key-value storage of the tree nodes and roots, e.g.
+// memorydb.Database or leveldb.Database from go-ethereum
+type Database interface {
+	ethdb.KeyValueReader
+	ethdb.KeyValueWriter
+	ethdb.Batcher
+}
+
+// Database keys: nodes and root infos by hash, and the current root
+var (
+	nodePrefix     = []byte("smt-node-")
+	rootInfoPrefix = []byte("smt-root-")
+	currentRootKey = []byte("smt-current-root")
+)
+
+const (
+	leafNodeType   byte = 1
+	middleNodeType byte = 2
+)
+
+type node struct {
+	typ byte
+	// key and value for leaf, left and right for middle node
+	a, b [32]byte
+}
+
+// RootInfo is the root history entry
+type RootInfo struct {
+	Root      [32]byte
+	CreatedAt time.Time
+	// ReplacedAt is zero for the current root
+	ReplacedAt time.Time
+}
+
+// Tree is Poseidon sparse Merkle tree with the same semantics as the
+// SparseMerkleTree library of PoseidonSMT and ProposalSMT contracts. The nodes
+// are content-addressed and never deleted, so the proofs can be generated for
+// any historical root. Tree is safe for concurrent use.
+type Tree struct {
+	mu       sync.RWMutex
+	db       Database
+	maxDepth int
+	root     [32]byte
+	now      func() time.Time
+}
+
+// NewTree creates the tree in the database, loading the current root if the
+// tree already exists. Use maxDepth of the contract, e.g. 80 for PoseidonSMT of
+// passport registration, to generate the same proofs.
+func NewTree(db Database, maxDepth int) (*Tree, error) {
+	if maxDepth <= 0 || maxDepth > MaxDepth {
+		return nil, fmt.Errorf("max depth must be in range (0, %d], got %d", MaxDepth, maxDepth)
+	}
+
+	t := &Tree{db: db, maxDepth: maxDepth, now: time.Now}
+
+	raw, err := get(db, currentRootKey)
+	if err != nil {
+		return nil, fmt.Errorf("get current root: %w", err)
+	}
+	copy(t.root[:], raw)
+
+	return t, nil
+}
+
+// NewMemoryTree creates the tree in memory, e.g. for test fixtures
+func NewMemoryTree(maxDepth int) (*Tree, error) {
+	return NewTree(memorydb.New(), maxDepth)
+}
+
+// NewLevelDBTree creates or opens the tree persisted in LevelDB directory. Close
+// the returned database on shutdown.
+func NewLevelDBTree(dir string, maxDepth int) (*Tree, *leveldb.Database, error) {
+	db, err := leveldb.New(dir, 0, 0, "", false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open leveldb: %w", err)
+	}
+
+	t, err := NewTree(db, maxDepth)
+	if err != nil {
+		_ = db.Close()
+		return nil, nil, err
+	}
+
+	return t, db, nil
+}
+
+// Root returns the current root, which is zero for empty tree
+func (t *Tree) Root() [32]byte {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.root
+}
+
+// RootInfo returns the history entry of the root. The second value is false
+// when the root has never been the tree root.
+func (t *Tree) RootInfo(root [32]byte) (RootInfo, bool, error) {
+	raw, err := get(t.db, append(rootInfoPrefix, root[:]...))
+	if err != nil || raw == nil {
+		return RootInfo{}, false, err
+	}
+
+	info := RootInfo{Root: root, CreatedAt: time.Unix(0, int64(binary.BigEndian.Uint64(raw[:8])))}
+	if replaced := binary.BigEndian.Uint64(raw[8:]); replaced != 0 {
+		info.ReplacedAt = time.Unix(0, int64(replaced))
+	}
+
+	return info, true, nil
+}
+
+// Add inserts the new key, returning ErrKeyExists for existing one. The key and
+// value must be in BN254 field.
+func (t *Tree) Add(key, value [32]byte) error {
+	return t.modify(func(root [32]byte) ([32]byte, error) {
+		return t.add(root, node{typ: leafNodeType, a: key, b: value}, 0)
+	})
+}
+
+// Update sets the value of existing key, returning ErrKeyNotFound otherwise
+func (t *Tree) Update(key, value [32]byte) error {
+	return t.modify(func(root [32]byte) ([32]byte, error) {
+		return t.update(root, node{typ: leafNodeType, a: key, b: value}, 0)
+	})
+}
+
+// Remove deletes existing key, returning ErrKeyNotFound otherwise
+func (t *Tree) Remove(key [32]byte) error {
+	return t.modify(func(root [32]byte) ([32]byte, error) {
+		return t.remove(root, key, 0)
+	})
+}
+
+// Get returns the value of the key. The second value is false when the key is
+// not in the tree.
+func (t *Tree) Get(key [32]byte) ([32]byte, bool, error) {
+	proof, err := t.GetProof(key)
+	return proof.Value, proof.Existence, err
+}
+
+// GetProof returns the proof of the key for the current root in the same
+// layout as GetProof of the contracts: siblings are padded to max depth.
+func (t *Tree) GetProof(key [32]byte) (Proof, error) {
+	return t.GetProofAt(t.Root(), key)
+}
+
+// GetProofAt returns the proof of the key for the historical root
+func (t *Tree) GetProofAt(root, key [32]byte) (Proof, error) {
+	proof := Proof{
+		Root:     root,
+		Siblings: make([][32]byte, t.maxDepth),
+		Key:      key,
+	}
+
+	current := root
+	for depth := 0; ; depth++ {
+		n, err := t.getNode(current)
+		if err != nil {
+			return Proof{}, err
+		}
+
+		switch n.typ {
+		case 0:
+			return proof, nil
+		case leafNodeType:
+			if n.a == key {
+				proof.Existence = true
+				proof.Value = n.b
+			} else {
+				proof.AuxExistence = true
+				proof.AuxKey = n.a
+				proof.AuxValue = n.b
+			}
+			return proof, nil
+		}
+
+		if depth >= t.maxDepth {
+			return Proof{}, fmt.Errorf("%w: corrupted tree at %x", ErrMaxDepth, current)
+		}
+
+		if bit(key, depth) == 0 {
+			current, proof.Siblings[depth] = n.a, n.b
+		} else {
+			current, proof.Siblings[depth] = n.b, n.a
+		}
+	}
+}
+
+// modify applies the change to the current root and records the root history
+func (t *Tree) modify(change func(root [32]byte) ([32]byte, error)) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	newRoot, err := change(t.root)
+	if err != nil || newRoot == t.root {
+		return err
+	}
+
+	var (
+		now   = t.now()
+		batch = t.db.NewBatch()
+	)
+
+	if err = batch.Put(append(rootInfoPrefix, t.root[:]...), encodeRootInfo(t.created(t.root), now)); err != nil {
+		return fmt.Errorf("put replaced root info: %w", err)
+	}
+	if err = batch.Put(append(rootInfoPrefix, newRoot[:]...), encodeRootInfo(now, time.Time{})); err != nil {
+		return fmt.Errorf("put new root info: %w", err)
+	}
+	if err = batch.Put(currentRootKey, newRoot[:]); err != nil {
+		return fmt.Errorf("put current root: %w", err)
+	}
+	if err = batch.Write(); err != nil {
+		return fmt.Errorf("write root: %w", err)
+	}
+
+	t.root = newRoot
+	return nil
+}
+
+// created returns the creation time of the root, zero time for the initial
+// empty root
+func (t *Tree) created(root [32]byte) time.Time {
+	info, ok, err := t.RootInfo(root)
+	if err != nil || !ok {
+		return time.Time{}
+	}
+	return info.CreatedAt
+}
+
+func (t *Tree) add(current [32]byte, leaf node, depth int) ([32]byte, error) {
+	n, err := t.getNode(current)
+	if err != nil {
+		return [32]byte{}, err
+	}
+
+	switch n.typ {
+	case 0:
+		return t.putNode(leaf)
+	case leafNodeType:
+		if n.a == leaf.a {
+			return [32]byte{}, ErrKeyExists
+		}
+		return t.pushDown(n, leaf, depth)
+	}
+
+	if depth >= t.maxDepth {
+		return [32]byte{}, ErrMaxDepth
+	}
+
+	if bit(leaf.a, depth) == 0 {
+		if n.a, err = t.add(n.a, leaf, depth+1); err != nil {
+			return [32]byte{}, err
+		}
+	} else {
+		if n.b, err = t.add(n.b, leaf, depth+1); err != nil {
+			return [32]byte{}, err
+		}
+	}
+
+	return t.putNode(n)
+}
+
+// pushDown creates middle nodes for the two leaves with the same path until
+// their paths diverge
+func (t *Tree) pushDown(old, leaf node, depth int) ([32]byte, error) {
+	if depth >= t.maxDepth {
+		return [32]byte{}, ErrMaxDepth
+	}
+
+	oldBit, newBit := bit(old.a, depth), bit(leaf.a, depth)
+	if oldBit == newBit {
+		child, err := t.pushDown(old, leaf, depth+1)
+		if err != nil {
+			return [32]byte{}, err
+		}
+
+		middle := node{typ: middleNodeType}
+		if newBit == 0 {
+			middle.a = child
+		} else {
+			middle.b = child
+		}
+		return t.putNode(middle)
+	}
+
+	oldHash, err := t.putNode(old)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	newHash, err := t.putNode(leaf)
+	if err != nil {
+		return [32]byte{}, err
+	}
+
+	if newBit == 0 {
+		return t.putNode(node{typ: middleNodeType, a: newHash, b: oldHash})
+	}
+	return t.putNode(node{typ: middleNodeType, a: oldHash, b: newHash})
+}
+
+func (t *Tree) update(current [32]byte, leaf node, depth int) ([32]byte, error) {
+	n, err := t.getNode(current)
+	if err != nil {
+		return [32]byte{}, err
+	}
+
+	switch n.typ {
+	case 0:
+		return [32]byte{}, ErrKeyNotFound
+	case leafNodeType:
+		if n.a != leaf.a {
+			return [32]byte{}, ErrKeyNotFound
+		}
+		return t.putNode(leaf)
+	}
+
+	if bit(leaf.a, depth) == 0 {
+		if n.a, err = t.update(n.a, leaf, depth+1); err != nil {
+			return [32]byte{}, err
+		}
+	} else {
+		if n.b, err = t.update(n.b, leaf, depth+1); err != nil {
+			return [32]byte{}, err
+		}
+	}
+
+	return t.putNode(n)
+}
+
+// remove deletes the leaf and moves its sibling leaf up, while it is the only
+// child of the middle node
+func (t *Tree) remove(current, key [32]byte, depth int) ([32]byte, error) {
+	n, err := t.getNode(current)
+	if err != nil {
+		return [32]byte{}, err
+	}
+
+	switch n.typ {
+	case 0:
+		return [32]byte{}, ErrKeyNotFound
+	case leafNodeType:
+		if n.a != key {
+			return [32]byte{}, ErrKeyNotFound
+		}
+		return [32]byte{}, nil
+	}
+
+	var child, sibling [32]byte
+	if bit(key, depth) == 0 {
+		if child, err = t.remove(n.a, key, depth+1); err != nil {
+			return [32]byte{}, err
+		}
+		n.a, sibling = child, n.b
+	} else {
+		if child, err = t.remove(n.b, key, depth+1); err != nil {
+			return [32]byte{}, err
+		}
+		n.b, sibling = child, n.a
+	}
+
+	switch {
+	case child == [32]byte{} && t.isLeaf(sibling):
+		return sibling, nil
+	case sibling == [32]byte{} && (child == [32]byte{} || t.isLeaf(child)):
+		return child, nil
+	}
+
+	return t.putNode(n)
+}
+
+func (t *Tree) isLeaf(hash [32]byte) bool {
+	n, err := t.getNode(hash)
+	return err == nil && n.typ == leafNodeType
+}
+
+func (t *Tree) getNode(hash [32]byte) (node, error) {
+	if hash == [32]byte{} {
+		return node{}, nil
+	}
+
+	raw, err := get(t.db, append(nodePrefix, hash[:]...))
+	if err != nil {
+		return node{}, fmt.Errorf("get node %x: %w", hash, err)
+	}
+	if len(raw) != 65 {
+		return node{}, fmt.Errorf("node %x is missing or corrupted", hash)
+	}
+
+	n := node{typ: raw[0]}
+	copy(n.a[:], raw[1:33])
+	copy(n.b[:], raw[33:])
+
+	return n, nil
+}
+
+func (t *Tree) putNode(n node) ([32]byte, error) {
+	hash, err := n.hash()
+	if err != nil {
+		return [32]byte{}, err
+	}
+
+	raw := make([]byte, 0, 65)
+	raw = append(raw, n.typ)
+	raw = append(raw, n.a[:]...)
+	raw = append(raw, n.b[:]...)
+
+	if err = t.db.Put(append(nodePrefix, hash[:]...), raw); err != nil {
+		return [32]byte{}, fmt.Errorf("put node %x: %w", hash, err)
+	}
+
+	return hash, nil
+}
+
+func (n node) hash() (hash [32]byte, err error) {
+	var (
+		a = new(big.Int).SetBytes(n.a[:])
+		b = new(big.Int).SetBytes(n.b[:])
+		h *big.Int
+	)
+
+	if n.typ == leafNodeType {
+		h, err = HashLeaf(a, b)
+	} else {
+		h, err = HashNode(a, b)
+	}
+	if err != nil {
+		return hash, fmt.Errorf("hash node: %w", err)
+	}
+
+	h.FillBytes(hash[:])
+	return hash, nil
+}
+
+// bit returns the bit of the key path at depth, starting from the least
+// significant bit of the big-endian key
+func bit(key [32]byte, depth int) uint {
+	return uint(key[31-depth/8]>>(depth%8)) & 1
+}
+
+func get(db ethdb.KeyValueReader, key []byte) ([]byte, error) {
+	ok, err := db.Has(key)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return db.Get(key)
+}
+
+func encodeRootInfo(created, replaced time.Time) []byte {
+	raw := make([]byte, 16)
+	binary.BigEndian.PutUint64(raw[:8], uint64(unixNano(created)))
+	binary.BigEndian.PutUint64(raw[8:], uint64(unixNano(replaced)))
+	return raw
+}
+
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
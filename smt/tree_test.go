@@ -0,0 +1,166 @@
+package smt
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDepth = 80
+
+func randomKeys(n int) [][32]byte {
+	rnd := rand.New(rand.NewSource(42))
+	keys := make([][32]byte, n)
+	for i := range keys {
+		// keep the keys in the field
+		rnd.Read(keys[i][1:])
+	}
+	return keys
+}
+
+func newTree(t *testing.T, keys [][32]byte) *Tree {
+	tree, err := NewMemoryTree(testDepth)
+	require.NoError(t, err)
+
+	for i, key := range keys {
+		require.NoError(t, tree.Add(key, bytes32(big.NewInt(int64(i+1)))))
+	}
+	return tree
+}
+
+func TestTree(t *testing.T) {
+	keys := randomKeys(50)
+	tree := newTree(t, keys)
+	root := tree.Root()
+
+	for i, key := range keys {
+		proof, err := tree.GetProof(key)
+		require.NoError(t, err)
+		assert.True(t, proof.Existence)
+		assert.Equal(t, bytes32(big.NewInt(int64(i+1))), proof.Value)
+		assert.Len(t, proof.Siblings, testDepth)
+		assert.NoError(t, proof.VerifyRoot(root))
+	}
+
+	for _, key := range randomKeys(60)[50:] {
+		proof, err := tree.GetProof(key)
+		require.NoError(t, err)
+		assert.False(t, proof.Existence)
+		assert.NoError(t, proof.VerifyRoot(root))
+	}
+
+	assert.ErrorIs(t, tree.Add(keys[0], bytes32(big.NewInt(1))), ErrKeyExists)
+	assert.ErrorIs(t, tree.Update(randomKeys(51)[50], bytes32(big.NewInt(1))), ErrKeyNotFound)
+	assert.ErrorIs(t, tree.Remove(randomKeys(51)[50]), ErrKeyNotFound)
+
+	require.NoError(t, tree.Update(keys[0], bytes32(big.NewInt(100))))
+	value, ok, err := tree.Get(keys[0])
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, bytes32(big.NewInt(100)), value)
+
+	// historical proof has the old value
+	proof, err := tree.GetProofAt(root, keys[0])
+	require.NoError(t, err)
+	assert.Equal(t, bytes32(big.NewInt(1)), proof.Value)
+	assert.NoError(t, proof.VerifyRoot(root))
+}
+
+func TestTreeRemove(t *testing.T) {
+	keys := randomKeys(30)
+	tree := newTree(t, keys)
+
+	// removal must lead to the same root as if the key was never added
+	for i := len(keys) - 1; i >= 0; i-- {
+		require.NoError(t, tree.Remove(keys[i]))
+		assert.Equal(t, newTree(t, keys[:i]).Root(), tree.Root(), "after removing key %d", i)
+	}
+	assert.Equal(t, [32]byte{}, tree.Root())
+}
+
+func TestTreeRootHistory(t *testing.T) {
+	tree, err := NewMemoryTree(testDepth)
+	require.NoError(t, err)
+
+	now := time.Unix(1700000000, 0)
+	tree.now = func() time.Time { return now }
+
+	require.NoError(t, tree.Add(bytes32(big.NewInt(1)), bytes32(big.NewInt(1))))
+	first := tree.Root()
+
+	now = now.Add(time.Hour)
+	require.NoError(t, tree.Add(bytes32(big.NewInt(2)), bytes32(big.NewInt(2))))
+
+	info, ok, err := tree.RootInfo(first)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(-time.Hour), info.CreatedAt)
+	assert.Equal(t, now, info.ReplacedAt)
+
+	info, ok, err = tree.RootInfo(tree.Root())
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, info.ReplacedAt.IsZero())
+
+	_, ok, err = tree.RootInfo([32]byte{1})
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestLevelDBTree(t *testing.T) {
+	dir := t.TempDir()
+	keys := randomKeys(10)
+
+	tree, db, err := NewLevelDBTree(dir, testDepth)
+	require.NoError(t, err)
+	for _, key := range keys {
+		require.NoError(t, tree.Add(key, key))
+	}
+	root := tree.Root()
+	require.NoError(t, db.Close())
+
+	tree, db, err = NewLevelDBTree(dir, testDepth)
+	require.NoError(t, err)
+	defer db.Close()
+
+	assert.Equal(t, root, tree.Root())
+	proof, err := tree.GetProof(keys[3])
+	require.NoError(t, err)
+	assert.True(t, proof.Existence)
+	assert.NoError(t, proof.VerifyRoot(root))
+}
+
+func TestTreeKnownRoot(t *testing.T) {
+	tree, err := NewMemoryTree(testDepth)
+	require.NoError(t, err)
+	assert.Equal(t, [32]byte{}, tree.Root())
+
+	for _, kv := range [][2]int64{{1, 100}, {2, 200}, {3, 300}} {
+		require.NoError(t, tree.Add(bytes32(big.NewInt(kv[0])), bytes32(big.NewInt(kv[1]))))
+	}
+
+	// by the first bit 2 goes left, 1 and 3 go right, then by the second bit
+	// 1 goes left and 3 goes right
+	var (
+		leaf1 = must(HashLeaf(big.NewInt(1), big.NewInt(100)))
+		leaf2 = must(HashLeaf(big.NewInt(2), big.NewInt(200)))
+		leaf3 = must(HashLeaf(big.NewInt(3), big.NewInt(300)))
+		root  = must(HashNode(leaf2, must(HashNode(leaf1, leaf3))))
+	)
+	got := tree.Root()
+	assert.Equal(t, bytes32(root), got)
+	// pinned to catch any change of the layout
+	assert.Equal(t,
+		"8881322987570547271746563421239657649865373532945042450591897535849937520955",
+		new(big.Int).SetBytes(got[:]).String())
+
+	// siblings are ordered from the root
+	proof, err := tree.GetProof(bytes32(big.NewInt(3)))
+	require.NoError(t, err)
+	assert.Equal(t, [][32]byte{bytes32(leaf2), bytes32(leaf1)}, proof.Siblings[:2])
+	assert.Equal(t, [32]byte{}, proof.Siblings[2])
+}
@@ -0,0 +1,85 @@
+package zkverifier_kit
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	val "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/rarimo/zkverifier-kit/root"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/rarimo/zkverifier-kit"
+
+// Span attributes of verification. Public signal values are never recorded,
+// only the configured options and the results.
+const (
+	AttrProofType    = attribute.Key("zkverifier.proof_type")
+	AttrEventID      = attribute.Key("zkverifier.event_id")
+	AttrRootVerifier = root.AttrVerifierType
+	// AttrFailedRules contains the keys of validation.Errors, e.g. "pub_signals/citizenship"
+	AttrFailedRules = attribute.Key("zkverifier.failed_rules")
+	AttrErrorCodes  = attribute.Key("zkverifier.error_codes")
+)
+
+// startSpan starts the span in the options context and replaces the context
+// with the span one until the returned function is called with the result
+func (o *VerifyOptions) startSpan(name string, attrs ...attribute.KeyValue) func(error) {
+	parent := o.ctx
+	ctx, span := o.tracer.Start(parent, name, trace.WithAttributes(attrs...))
+	o.ctx = ctx
+
+	return func(err error) {
+		o.ctx = parent
+		endSpan(span, err)
+	}
+}
+
+// startStage starts the span of verification stage and reports its duration to
+// the Observer on end
+func (o *VerifyOptions) startStage(stage Stage, attrs ...attribute.KeyValue) func(error) {
+	start := time.Now()
+	end := o.startSpan("zkverifier."+string(stage), attrs...)
+
+	return func(err error) {
+		o.observer.ObserveStage(o.proofType.String(), stage, time.Since(start))
+		end(err)
+	}
+}
+
+func (o *VerifyOptions) spanAttributes() []attribute.KeyValue {
+	attrs := []attribute.KeyValue{AttrProofType.String(o.proofType.String())}
+	if o.eventID != "" {
+		attrs = append(attrs, AttrEventID.String(o.eventID))
+	}
+	return attrs
+}
+
+func rootVerifierAttribute(v root.Verifier) attribute.KeyValue {
+	return AttrRootVerifier.String(string(root.TypeOf(v)))
+}
+
+// endSpan sets the failed rules of validation errors. The messages of internal
+// errors are not recorded, because they may contain private data or RPC URL.
+func endSpan(span trace.Span, err error) {
+	var errs val.Errors
+	switch {
+	case err == nil:
+	case errors.As(err, &errs):
+		keys := make([]string, 0, len(errs))
+		for key := range errs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		span.SetAttributes(AttrFailedRules.StringSlice(keys), AttrErrorCodes.StringSlice(errorCodes(errs)))
+		span.SetStatus(codes.Error, "proof is invalid")
+	default:
+		span.SetStatus(codes.Error, "internal error")
+	}
+
+	span.End()
+}
@@ -1,22 +1,29 @@
 package zkverifier_kit
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"math/big"
 	"time"
 
 	val "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/rarimo/zkverifier-kit/country"
+	"github.com/rarimo/zkverifier-kit/zkdate"
 )
 
 type (
 	eventData []byte
 
+	// excludedMask contains Alpha-3 codes which must be set in the citizenship mask
+	excludedMask []string
+
 	timeRule struct {
+		field       zkdate.Field
 		point       time.Time
 		isBefore    bool
 		isEqualDate bool
+		// tolerance - allowed difference in days for equal dates check
+		tolerance int
 	}
 )
 
@@ -26,31 +33,50 @@ func (val eventData) Validate(data interface{}) error {
 		return fmt.Errorf("invalid type: %T, expected string", data)
 	}
 
-	if !bytes.Equal([]byte(decodeInt(str)), val) {
+	// compare as integers, because leading zero bytes are lost in the signal
+	signal, ok := new(big.Int).SetString(str, 10)
+	if !ok || signal.Cmp(new(big.Int).SetBytes(val)) != 0 {
 		return fmt.Errorf("event data does not match")
 	}
 
 	return nil
 }
 
-func (r timeRule) Validate(date interface{}) error {
-	raw, ok := date.(string)
+func (m excludedMask) Validate(data interface{}) error {
+	str, ok := data.(string)
 	if !ok {
-		return fmt.Errorf("invalid type: %T, expected string", date)
+		return fmt.Errorf("invalid type: %T, expected string", data)
 	}
 
-	bigDecimalDate, ok := new(big.Int).SetString(raw, 10)
+	mask, ok := new(big.Int).SetString(str, 10)
+	if !ok {
+		return errors.New("invalid citizenship mask")
+	}
+
+	for _, code := range m {
+		// unknown codes are rejected on options validation
+		i, _ := country.Index(code)
+		if mask.Bit(i) == 0 {
+			return fmt.Errorf("citizenship %s is not excluded", code)
+		}
+	}
+
+	return nil
+}
+
+func (r timeRule) Validate(date interface{}) error {
+	raw, ok := date.(string)
 	if !ok {
-		return fmt.Errorf("failed to set string: %T", date)
+		return fmt.Errorf("invalid type: %T, expected string", date)
 	}
 
-	parsed, err := time.Parse("060102", string(bigDecimalDate.Bytes()))
+	parsed, err := zkdate.Decode(raw, r.field)
 	if err != nil {
 		return fmt.Errorf("invalid date string: %w", err)
 	}
 
 	if r.isEqualDate {
-		if !datesEqual(r.point, parsed) {
+		if !datesEqual(r.point, parsed, r.tolerance) {
 			return errors.New("dates are not equal")
 		}
 		return nil
@@ -67,34 +93,52 @@ func (r timeRule) Validate(date interface{}) error {
 	return nil
 }
 
-func datesEqual(one time.Time, another time.Time) bool {
-	return one.Format(time.DateOnly) == another.Format(time.DateOnly)
+// datesEqual compares dates without time, allowing them to differ by tolerance days
+func datesEqual(one time.Time, another time.Time, tolerance int) bool {
+	diff := truncateDate(one).Sub(truncateDate(another))
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= time.Duration(tolerance)*24*time.Hour
+}
+
+func truncateDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
 }
 
-func beforeDate(point time.Time) timeRule {
+func beforeDate(field zkdate.Field, point time.Time) timeRule {
 	return timeRule{
+		field:       field,
 		point:       point,
 		isBefore:    true,
 		isEqualDate: false,
 	}
 }
 
-func afterDate(point time.Time) timeRule {
+func afterDate(field zkdate.Field, point time.Time) timeRule {
 	return timeRule{
+		field:       field,
 		point:       point,
 		isBefore:    false,
 		isEqualDate: false,
 	}
 }
 
-func equalDate(point time.Time) timeRule {
+func equalDate(field zkdate.Field, point time.Time) timeRule {
 	return timeRule{
+		field:       field,
 		point:       point,
 		isBefore:    false,
 		isEqualDate: true,
 	}
 }
 
+// withTolerance allows the dates in equalDate rule to differ by the given days
+func (r timeRule) withTolerance(days int) timeRule {
+	r.tolerance = days
+	return r
+}
+
 // decode big int from the proof to string
 func decodeInt(s string) string {
 	b, ok := new(big.Int).SetString(s, 10)
@@ -104,6 +148,28 @@ func decodeInt(s string) string {
 	return string(b.Bytes())
 }
 
+// decodeCitizenship decodes ICAO 9303 nationality code from the proof and
+// normalizes it to ISO 3166-1 alpha-3 code, British nationality or a special
+// category. British nationalities are converted to "GBR" when foldBritish is set.
+func decodeCitizenship(s string, foldBritish bool) string {
+	code, _ := country.Normalize(decodeInt(s))
+	if foldBritish {
+		return country.FoldBritish(code)
+	}
+	return code
+}
+
+// codedRule sets the code to the error of the rule, when the signal is checked
+// by several rules with different codes, e.g. disclosed BirthDate
+func (o *VerifyOptions) codedRule(rule val.Rule, code ErrorCode) val.Rule {
+	return val.By(func(value interface{}) error {
+		if err := rule.Validate(value); err != nil {
+			return &Error{Code: code, Err: err, Params: o.errorParams(code)}
+		}
+		return nil
+	})
+}
+
 func validateOnOptSet(value, option any, rules val.Rule) error {
 	return val.Validate(value, val.When(
 		!val.IsEmpty(option),
@@ -0,0 +1,104 @@
+// Package zkdate encodes and decodes dates in the format used by public signals
+// of passport proofs: ASCII string "YYMMDD", which bytes are packed into a big
+// integer in decimal representation, e.g. "240419" is 55199728415033.
+package zkdate
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// Field defines how the century of two-digit year is resolved
+type Field int
+
+const (
+	// Birth resolves the date to the past: it is the latest date not after the
+	// reference one. Use it for birth dates and their bounds.
+	Birth Field = iota
+	// Expiry resolves the date to the century, where it is within 50 years
+	// from the reference date, so the future dates are never moved to the
+	// past, while the recent past stays in place. Use it for expiration dates,
+	// their bounds and current dates.
+	Expiry
+)
+
+// Raw values of the dates, which are set by ZKP when the date is not used
+const (
+	// Zero is set when the date is not used in the circuit
+	Zero = "0"
+	// Unset is "000000" encoded, it is set when the date is not present in selector
+	Unset = "52983525027888"
+)
+
+var (
+	ErrZero  = errors.New("date is zero")
+	ErrUnset = errors.New("date is unset")
+)
+
+const expiryWindow = 50
+
+// Encode converts date to the public signal format, the century is omitted
+func Encode(date time.Time) string {
+	return new(big.Int).SetBytes([]byte(date.Format("060102"))).String()
+}
+
+// IsEmpty checks whether the signal is one of the empty values, see Zero and Unset
+func IsEmpty(signal string) bool {
+	return signal == Zero || signal == Unset
+}
+
+// Decode parses the public signal to UTC date, resolving the century relative
+// to the current date, see DecodeAt. ErrZero and ErrUnset are returned on empty
+// values.
+func Decode(signal string, field Field) (time.Time, error) {
+	return DecodeAt(signal, field, time.Now().UTC())
+}
+
+// DecodeAt parses the public signal to UTC date, resolving the century
+// according to the field relative to the reference date
+func DecodeAt(signal string, field Field, ref time.Time) (time.Time, error) {
+	switch signal {
+	case Zero:
+		return time.Time{}, ErrZero
+	case Unset:
+		return time.Time{}, ErrUnset
+	}
+
+	packed, ok := new(big.Int).SetString(signal, 10)
+	if !ok || packed.Sign() < 0 {
+		return time.Time{}, errors.New("invalid decimal number")
+	}
+
+	raw := string(packed.Bytes())
+	parsed, err := time.Parse("060102", raw)
+	if err != nil || len(raw) != 6 {
+		return time.Time{}, errors.New("not a YYMMDD date")
+	}
+
+	// time.Parse has already validated the date, so only the year is changed
+	yy := parsed.Year() % 100
+	year := ref.Year() - ref.Year()%100 + yy
+
+	switch field {
+	case Birth:
+		if date(year, parsed).After(ref) {
+			year -= 100
+		}
+	case Expiry:
+		if year >= ref.Year()+expiryWindow {
+			year -= 100
+		} else if year < ref.Year()-expiryWindow {
+			year += 100
+		}
+	default:
+		return time.Time{}, fmt.Errorf("unknown field %d", field)
+	}
+
+	return date(year, parsed), nil
+}
+
+func date(year int, t time.Time) time.Time {
+	return time.Date(year, t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,79 @@
+package zkdate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeAt(t *testing.T) {
+	ref := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name   string
+		signal string
+		field  Field
+		want   time.Time
+		err    string
+	}{
+		{
+			name:   "Birth date in the previous century",
+			signal: Encode(time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)),
+			field:  Birth,
+			want:   time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "Birth date in the current century",
+			signal: "55199728415033", // 240419
+			field:  Birth,
+			want:   time.Date(2024, 4, 19, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "Birth date after reference",
+			signal: Encode(time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)),
+			field:  Birth,
+			want:   time.Date(1926, 10, 20, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "Expiration date after 2069",
+			signal: Encode(time.Date(2070, 5, 1, 0, 0, 0, 0, time.UTC)),
+			field:  Expiry,
+			want:   time.Date(2070, 5, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "Expiration date in the past",
+			signal: Encode(time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)),
+			field:  Expiry,
+			want:   time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "Zero date",
+			signal: Zero,
+			err:    ErrZero.Error(),
+		},
+		{
+			name:   "Unset date",
+			signal: Unset,
+			err:    ErrUnset.Error(),
+		},
+		{
+			name:   "Invalid date",
+			signal: Encode(time.Date(2024, 4, 19, 0, 0, 0, 0, time.UTC)) + "1",
+			err:    "not a YYMMDD date",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := DecodeAt(tc.signal, tc.field, ref)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}